@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// playerAnalysis adds up what a player did over a game
+type playerAnalysis struct {
+	moves, sameAsGreedy                 int
+	wallPoints, floorPenalties, bonuses int
+}
+
+// analyze replays a saved game, and compares each move with the greedy bot's
+func analyze(args []string) {
+	flags := newFlagSet("analyze", "<savefile>", "Replays a saved game, and lists the moves that differ from the one the greedy bot would have made in the same place. Then it shows where each player's points came from.")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gameLog, err := loadSaveFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	analyses := make([]playerAnalysis, len(gameLog.Players))
+	game := gameLog.NewGame(models.WithObserver(models.ObserverFunc(func(event models.Event) {
+		switch e := event.(type) {
+		case models.WallTiled:
+			analyses[e.Player].wallPoints += e.WallScore.Score
+		case models.FloorScored:
+			analyses[e.Player].floorPenalties += e.Penalty
+		case models.GameEnded:
+			for player, bonus := range e.Bonuses {
+				analyses[player].bonuses += bonus.Total()
+			}
+		}
+	})))
+
+	greedy := bots.GreedyBot{}
+	for i, move := range gameLog.Moves {
+		player := game.CurrentPlayerKey
+		suggestion, err := greedy.ChooseMove(game)
+		if err != nil {
+			fmt.Printf("move #%d can't be analyzed: %s\n", i+1, err)
+			os.Exit(1)
+		}
+		analyses[player].moves++
+		if suggestion == move {
+			analyses[player].sameAsGreedy++
+		} else {
+			fmt.Printf("#%d %s: %s, where the greedy bot would play %s\n", i+1, game.Players[player].Name, move, suggestion)
+		}
+
+		if err := game.TakeTurn(move); err != nil {
+			fmt.Printf("move #%d can't be replayed: %s\n", i+1, err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println()
+	if !game.GameOver {
+		fmt.Printf("The game isn't over yet, these are the totals after %d moves.\n", len(gameLog.Moves))
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PLAYER\tMOVES\tSAME AS GREEDY\tWALL POINTS\tFLOOR PENALTIES\tBONUSES\tSCORE")
+	for player, a := range analyses {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", game.Players[player].Name, a.moves, a.sameAsGreedy,
+			a.wallPoints, a.floorPenalties, a.bonuses, game.Players[player].Board.Score)
+	}
+	table.Flush()
+}
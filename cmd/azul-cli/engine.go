@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+)
+
+// engine runs one of the built-in bots as an engine on stdin and stdout, which is handy for
+// trying out the engine protocol, or as an opponent that speaks it
+func engine(args []string) {
+	flags := newFlagSet("engine", "<bot>", fmt.Sprintf("Runs the bot as an engine on stdin and stdout. The bot is one of %v.", bots.Names()))
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	bot, err := bots.New(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := bots.ServeEngine(flags.Arg(0), bot, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/aaron-zeisler/azul/internal/client"
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// join plays a seat in a game on a server. The board is shown after every move, and the player
+// is only prompted when it's their turn.
+func join(args []string) {
+	flags := newFlagSet("join", "<server> <game-id>", "Plays a seat in a game on a server, and asks for a move whenever it's the seat's turn.")
+	seat := flags.Int("seat", -1, "the seat to join (the first free seat if it's missing)")
+	name := flags.String("name", "", "the player's name")
+	token := flags.String("token", "", "the token of a seat that's already been joined, to rejoin it")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	gameID := flags.Arg(1)
+	c := client.NewClient(flags.Arg(0))
+
+	if *token == "" {
+		var err error
+		*seat, *token, err = joinSeat(c, gameID, *seat, *name)
+		if err != nil {
+			exitUnlessQuit(err)
+			return
+		}
+		fmt.Printf("You joined seat #%d. To rejoin it later, use -seat %d -token %s\n", *seat, *seat, *token)
+	} else if *seat < 0 {
+		fmt.Println("-seat is required with -token")
+		os.Exit(2)
+	}
+
+	if err := playRemoteGame(c, gameID, *seat, *token); err != nil {
+		exitUnlessQuit(err)
+	}
+}
+
+// joinSeat joins the seat, or the first free seat if seat is negative, and returns the seat
+// and its token. The player is asked for their name if it's empty.
+func joinSeat(c *client.Client, gameID string, seat int, name string) (int, string, error) {
+	if seat < 0 {
+		state, err := c.Game(gameID)
+		if err != nil {
+			return 0, "", err
+		}
+		for i, s := range state.Seats {
+			if !s.Joined {
+				seat = i
+				break
+			}
+		}
+		if seat < 0 {
+			return 0, "", fmt.Errorf("every seat in game '%s' has been taken", gameID)
+		}
+	}
+
+	for name == "" {
+		var err error
+		name, err = console.PromptForString("What is your name?")
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	response, err := c.JoinGame(gameID, seat, name)
+	if err != nil {
+		return 0, "", err
+	}
+	return seat, response.Token, nil
+}
+
+// playRemoteGame follows the game until it's over, and makes the seat's moves when it's the
+// seat's turn. The server decides whose turn it is and validates the moves, so an illegal
+// move is reported and the player is asked again.
+func playRemoteGame(c *client.Client, gameID string, seat int, token string) error {
+	stream, err := c.Stream(gameID)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// moved is the number of moves there were when the seat last made a move. States that were
+	// sent before the move reached the server still say it's the seat's turn, so they're skipped.
+	moved := -1
+	for message := range stream.Messages {
+		if message.Type != manager.MessageTypeState {
+			console.DisplayEvent(message.Event)
+			continue
+		}
+
+		state := message.Game
+		if state.Seats[seat].Forfeited || state.Seats[seat].Bot != "" {
+			fmt.Println("You're no longer playing this game")
+			return nil
+		}
+		switch state.Status {
+		case manager.GameStatusWaiting:
+			fmt.Println("WAITING FOR THE OTHER PLAYERS TO JOIN ...")
+			continue
+		case manager.GameStatusFinished:
+			console.DisplayGameState(models.RestoreGame(state.State))
+			return nil
+		}
+		if state.CurrentPlayer != seat {
+			fmt.Printf("WAITING FOR %s TO MOVE ...\n", state.Seats[state.CurrentPlayer].Name)
+			continue
+		}
+		if state.MoveCount <= moved {
+			continue
+		}
+
+		game := models.RestoreGame(state.State)
+		console.DisplayGameState(game)
+		fmt.Printf("IT'S YOUR TURN, %s\n", state.Seats[seat].Name)
+		accepted, err := promptForRemoteMove(c, gameID, token, game)
+		if err != nil {
+			return err
+		}
+		if accepted {
+			moved = state.MoveCount
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("the server closed the connection")
+}
+
+// promptForRemoteMove asks for a move until the server accepts one, and returns whether it
+// did. If it's no longer the player's turn, for example because their clock ran out or a
+// player was kicked, the error is shown and the game is followed until it's their turn again.
+func promptForRemoteMove(c *client.Client, gameID string, token string, game *models.Game) (bool, error) {
+	for {
+		move, err := console.PromptForMove(game)
+		if err != nil {
+			return false, err
+		}
+
+		_, err = c.MakeMove(gameID, token, move)
+		serverErr, ok := err.(client.ServerError)
+		switch {
+		case err == nil:
+			return true, nil
+		case ok && serverErr.StatusCode == http.StatusBadRequest:
+			fmt.Println(err)
+		case ok && (serverErr.StatusCode == http.StatusConflict || serverErr.StatusCode == http.StatusForbidden):
+			fmt.Println(err)
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+}
@@ -1,132 +1,107 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/aaron-zeisler/azul/internal/interactions"
-	"github.com/aaron-zeisler/azul/internal/models"
 )
 
-func main() {
-	fmt.Println("AZUL STARTING ...")
-	fmt.Println()
-
-	config := models.DefaultGameConfig
-
-	// Prompt for the number of players
-	playerSetup, err := interactions.PromptForNewPlayers(config)
-	if err != nil {
-		panic(err)
-	}
-
-	// Initialize the game
-	game := models.NewGame(
-		models.WithConfig(config),
-		models.WithPlayers(playerSetup.Players))
+// console is the terminal the CLI talks to the player on
+var console = interactions.NewSession(os.Stdin, os.Stdout,
+	interactions.WithTileRenderer(interactions.DefaultTileRenderer(os.Stdout)),
+	interactions.WithLayout(interactions.DefaultLayout()),
+	interactions.WithTerminalWidth(os.Stdout))
+
+// command is one of azul-cli's subcommands. Each command parses its own flags from the
+// arguments that follow its name.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
 
-	interactions.DisplayGameState(game)
-	fmt.Printf("Number of tiles left in the bag: %d\n", game.Bag.TileCount())
+// commands are listed by `azul-cli help` in this order
+var commands = []command{
+	{name: "play", summary: "play a game in the terminal", run: play},
+	{name: "turn", summary: "play one move of a game kept in a save file", run: turn},
+	{name: "replay", summary: "show the moves of a saved game", run: replay},
+	{name: "analyze", summary: "compare the moves of a saved game with the greedy bot's", run: analyze},
+	{name: "validate", summary: "check save files and game configs", run: validate},
+	{name: "match", summary: "play one game between bots", run: match},
+	{name: "simulate", summary: "play many games between bots, and report how each seat did", run: simulate},
+	{name: "tournament", summary: "play every pair of bots against each other, and rank them", run: tournament},
+	{name: "engine", summary: "run a built-in bot as an engine on stdin and stdout", run: engine},
+	{name: "serve", summary: "host games over HTTP, and over TCP with -tcp", run: serve},
+	{name: "join", summary: "play a seat in a game on a server", run: join},
+	{name: "watch", summary: "follow a game on a server", run: watch},
+}
 
-	// Find the first player and set them as the current player
-	var firstPlayerKey int
-	for i, player := range game.Players {
-		if player.IsFirstPlayer {
-			firstPlayerKey = i
-			break
-		}
+func main() {
+	args := os.Args[1:]
+	// Without a command, a game is played, so `azul-cli -players alice,bob` works as well
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		play(args)
+		return
 	}
-	currentPlayerKey := firstPlayerKey
-	currentPlayer := game.Players[currentPlayerKey]
-
-	// Set the current round, and the turn number within the round
-	//currentRound := 1
-	currentTurnNumber := 1
 
-	// This is the beginning of the game loop
-	for {
-		// Display which player's turn it is
-		fmt.Println()
-		fmt.Printf("CURRENT PLAYER: %s\n", currentPlayer.Name)
-
-		// Draw tiles from a factory or the center of the table
-		drawResponse, err := interactions.PromptToDrawFactoryTiles()
-		if err != nil {
-			panic(err)
-		}
-
-		var drawSource models.DrawSource
-		var drawDisplay string
-		if drawResponse.DrawSourceType == models.DrawSourceCenter {
-			drawSource = game.CenterOfTheTable
-			drawDisplay = "the center of the table"
-		} else {
-			drawSource = game.Factories[drawResponse.FactoryNumber]
-			drawDisplay = fmt.Sprintf("factory #%d", drawResponse.FactoryNumber)
-		}
-		fmt.Printf("Drawing %s tiles from %s\n", drawResponse.TileColor, drawDisplay)
-		drawnTiles, err := drawSource.DrawAllTilesByColor(drawResponse.TileColor)
-		if err != nil {
-			fmt.Println(err)
+	name, args := args[0], args[1:]
+	if name == "help" {
+		if len(args) == 0 {
+			usage(os.Stdout)
 			return
 		}
-
-		// Put the rest of the factory's tiles in the center of the table
-		if drawResponse.DrawSourceType == models.DrawSourceFactory {
-			leftovers := game.Factories[drawResponse.FactoryNumber].DrawAllTiles()
-			for _, tile := range leftovers {
-				game.CenterOfTheTable.AddTile(tile)
-			}
-		}
-
-		//Put the drawn tiles onto the player's game board (and/or floor)
-		placeResponse, err := interactions.PromptToPlaceFactoryTiles()
-		if err != nil {
-			panic(err)
-		}
-		fmt.Printf("Placing tiles on pattern line #%d\n", placeResponse.PatternLineNumber)
-		err = currentPlayer.Board.PlaceTiles(placeResponse.PatternLineNumber, drawnTiles)
-		if err != nil {
-			panic(err)
-		}
-
-		fmt.Println("After placing tiles:")
-		interactions.DisplayGameState(game)
-
-		// Move the pointer to the next player
-		currentPlayerKey++
-		if currentPlayerKey >= len(game.Players) {
-			currentPlayerKey = 0
-			currentTurnNumber++
-		}
-		currentPlayer = game.Players[currentPlayerKey]
-
-		// Check for the end of the round
-		if isRoundOver(game) {
-			// Score the round and show the game state
-			game.ScoreRound()
-
-			fmt.Println("TESTING -- GAME OVER")
-			fmt.Println("Here's the game state after scoring:")
-			interactions.DisplayGameState(game)
+		// The command shows its own help
+		name, args = args[0], []string{"-help"}
+	}
+	for _, c := range commands {
+		if c.name == name {
+			c.run(args)
 			return
 		}
 	}
-}
 
-func isRoundOver(game *models.Game) bool {
-	return factoriesAreEmpty(game) && centerOfTableIsEmpty(game)
+	fmt.Fprintf(os.Stderr, "'%s' is not a command\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
 }
 
-func factoriesAreEmpty(game *models.Game) bool {
-	for _, factory := range game.Factories {
-		if factory.HasTiles() {
-			return false
-		}
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: azul-cli <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The commands are:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use `azul-cli help <command>` for more about a command. Without a command, a game is played.")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Set %s=symbols to draw the tiles as letters instead of colors, or NO_COLOR=1 to write their names.\n", interactions.TileRendererVariable)
+	fmt.Fprintf(w, "Set %s=compact for smaller boards, or %s=summary for one line per player.\n", interactions.LayoutVariable, interactions.LayoutVariable)
+}
 
-	return true
+// newFlagSet creates the flag set for a command. Its help shows the command's arguments and
+// description, and then its flags.
+func newFlagSet(name string, arguments string, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: azul-cli %s [flags] %s\n", name, arguments)
+		fmt.Fprintln(flags.Output(), description)
+		flags.PrintDefaults()
+	}
+	return flags
 }
 
-func centerOfTableIsEmpty(game *models.Game) bool {
-	return !game.CenterOfTheTable.HasTiles()
+// exitUnlessQuit says goodbye if the player quit, and otherwise prints the error and exits
+func exitUnlessQuit(err error) {
+	if errors.As(err, &interactions.QuitError{}) {
+		fmt.Println("GOODBYE!")
+		return
+	}
+	fmt.Println(err)
+	os.Exit(1)
 }
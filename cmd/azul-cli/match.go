@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/history"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// match plays one game between bots, where each bot is either a built-in bot or an engine,
+// and prints the result
+func match(args []string) {
+	flags := newFlagSet("match", "<bot> <bot> ...", fmt.Sprintf("Plays a game between the bots, and shows each move. Each bot is one of %v, or engine:<command> to run an engine.", bots.Names()))
+	moveTime := flags.Duration("movetime", bots.DefaultMoveTime, "how long each engine has to choose a move")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "the seed for the game")
+	flags.Parse(args)
+	if err := models.DefaultGameConfig.ValidateNumberOfPlayers(flags.NArg()); err != nil {
+		fmt.Println(err)
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	players, names, err := newBots(flags.Args(), *moveTime)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer closeBots(players)
+
+	game, err := playBotGame(players, names, *seed, 0, func(name string, move models.Move) {
+		fmt.Printf("%s: %s\n", name, move)
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	displayFinalScores(game)
+}
+
+// newBots creates the bots from their descriptions (see newBot), and names them after their
+// seats. Engines that were started are closed if a later bot fails.
+func newBots(specs []string, moveTime time.Duration) ([]bots.Bot, []string, error) {
+	players := make([]bots.Bot, 0, len(specs))
+	names := make([]string, 0, len(specs))
+	for i, spec := range specs {
+		bot, name, err := newBot(spec, moveTime)
+		if err != nil {
+			closeBots(players)
+			return nil, nil, err
+		}
+		players = append(players, bot)
+		names = append(names, fmt.Sprintf("%s #%d", name, i))
+	}
+	return players, names, nil
+}
+
+// closeBots stops the bots that are engines
+func closeBots(players []bots.Bot) {
+	for _, bot := range players {
+		if engine, ok := bot.(*bots.Engine); ok {
+			engine.Close()
+		}
+	}
+}
+
+// restartFailedEngine starts the bot's engine again if it failed in an earlier game, so one
+// timeout or bad move only costs the engine the game it happened in. A built-in bot, or an
+// engine that hasn't failed, is returned as it is.
+func restartFailedEngine(bot bots.Bot, spec string, moveTime time.Duration) (bots.Bot, error) {
+	engine, ok := bot.(*bots.Engine)
+	if !ok || engine.Err() == nil {
+		return bot, nil
+	}
+	engine.Close()
+	restarted, _, err := newBot(spec, moveTime)
+	if err != nil {
+		return bot, err
+	}
+	return restarted, nil
+}
+
+// playBotGame plays a game between the bots, where the bot at index first goes first, and
+// calls onMove, if it's set, after each move. A bot that fails to make a legal move is
+// disqualified, and the game stops with an error.
+func playBotGame(players []bots.Bot, names []string, seed int64, first int, onMove func(name string, move models.Move)) (*models.Game, error) {
+	game := history.NewLog(models.DefaultGameConfig, seed, names, history.WithFirstPlayer(first)).NewGame()
+	for !game.GameOver {
+		name := game.CurrentPlayer().Name
+		move, err := players[game.CurrentPlayerKey].ChooseMove(game)
+		if err == nil {
+			err = game.TakeTurn(move)
+		}
+		if err != nil {
+			return game, fmt.Errorf("%s is disqualified: %w", name, err)
+		}
+		if onMove != nil {
+			onMove(name, move)
+		}
+	}
+	return game, nil
+}
+
+// newBot creates a bot from its description: the name of a built-in bot, or "engine:" followed
+// by the command that starts an engine. The bot's name is returned as well.
+func newBot(spec string, moveTime time.Duration) (bots.Bot, string, error) {
+	if !strings.HasPrefix(spec, "engine:") {
+		bot, err := bots.New(spec)
+		return bot, spec, err
+	}
+
+	command := strings.Fields(strings.TrimPrefix(spec, "engine:"))
+	if len(command) == 0 {
+		return nil, "", fmt.Errorf("'%s' is missing the engine's command", spec)
+	}
+	engine, err := bots.NewEngine(command[0], command[1:], bots.WithMoveTime(moveTime))
+	if err != nil {
+		return nil, "", err
+	}
+	return engine, engine.Name, nil
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/history"
+	"github.com/aaron-zeisler/azul/internal/interactions"
+	"github.com/aaron-zeisler/azul/internal/models"
+	"github.com/aaron-zeisler/azul/internal/tui"
+)
+
+// play runs a game in the terminal. The players take turns at the same keyboard, and the bots
+// among them play their own turns. Without flags, the players are asked for.
+func play(args []string) {
+	flags := newFlagSet("play", "", fmt.Sprintf("Plays a game at the keyboard. A player is a name, or bot:<bot> where the bot is one of %v, or bot:engine:<command> to run an engine.", bots.Names()))
+	players := flags.String("players", "", "the players, for example alice,bob,bot:greedy (they're asked for if it's missing)")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "the seed for the game, to play the same game again")
+	configPath := flags.String("config", "", "a JSON file with the config for a variant of the game")
+	firstPlayer := flags.String("first-player", "", "the name of the player who goes first (the first player listed if it's missing)")
+	fullScreen := flags.Bool("tui", false, "play in a full-screen view of the whole table, choosing the moves with the arrow keys")
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	fmt.Println("AZUL STARTING ...")
+	fmt.Println()
+
+	config := models.DefaultGameConfig
+	if *configPath != "" {
+		var err error
+		if config, err = loadConfig(*configPath); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	var specs []string
+	if *players != "" {
+		specs = strings.Split(*players, ",")
+	} else {
+		// Prompt for the number of players
+		playerSetup, err := console.PromptForNewPlayers(config)
+		if err != nil {
+			exitUnlessQuit(err)
+			return
+		}
+		for i := 0; i < len(playerSetup.Players); i++ {
+			specs = append(specs, playerSetup.Players[i].Name)
+		}
+	}
+
+	names, seats, err := setUpPlayers(config, specs)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for _, bot := range seats {
+		if engine, ok := bot.(*bots.Engine); ok {
+			defer engine.Close()
+		}
+	}
+	first := 0
+	if *firstPlayer != "" {
+		if first = indexOf(names, *firstPlayer); first < 0 {
+			fmt.Printf("-first-player must be one of the players %v\n", names)
+			os.Exit(2)
+		}
+	}
+
+	// Initialize the game
+	fmt.Printf("Seed: %d\n", *seed)
+	gameLog := history.NewLog(config, *seed, names, history.WithFirstPlayer(first))
+
+	var game *models.Game
+	if *fullScreen {
+		// The full screen is put away by now, so the last state of the game is shown on the console
+		game, err = playFullScreen(gameLog, seats)
+		if game != nil {
+			console.DisplayGameState(game)
+		}
+	} else {
+		game = gameLog.NewGame(models.WithObserver(models.ObserverFunc(console.DisplayEvent)))
+		err = playGame(game, seats, console, os.Stdout)
+	}
+	if err != nil {
+		exitUnlessQuit(err)
+		return
+	}
+	displayFinalScores(game)
+}
+
+// frontEnd is where the players see the game and choose their moves: the console, or the full
+// screen
+type frontEnd interface {
+	DisplayGameState(game *models.Game)
+	PromptForMove(game *models.Game) (models.Move, error)
+}
+
+// playGame plays the game to the end. The bots play their own turns, and the other players
+// choose their moves on the front end. Whose turn it is, and the bots' moves, are written to
+// out.
+func playGame(game *models.Game, seats map[int]bots.Bot, ui frontEnd, out io.Writer) error {
+	ui.DisplayGameState(game)
+	fmt.Fprintf(out, "Number of tiles left in the bag: %d\n", game.Bag.TileCount())
+
+	// This is the beginning of the game loop
+	for !game.GameOver {
+		// Display which player's turn it is
+		fmt.Fprintln(out)
+		fmt.Fprintf(out, "CURRENT PLAYER: %s\n", game.CurrentPlayer().Name)
+
+		var move models.Move
+		var err error
+		if bot, ok := seats[game.CurrentPlayerKey]; ok {
+			if move, err = bot.ChooseMove(game); err != nil {
+				return fmt.Errorf("%s failed to move: %w", game.CurrentPlayer().Name, err)
+			}
+			fmt.Fprintf(out, "%s: %s\n", game.CurrentPlayer().Name, move)
+		} else if move, err = ui.PromptForMove(game); err != nil {
+			return err
+		}
+		if err := game.TakeTurn(move); err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+
+		ui.DisplayGameState(game)
+	}
+	return nil
+}
+
+// playFullScreen plays the game on a full-screen view of the table. The terminal is put back
+// the way it was when it returns, even if the game panics.
+func playFullScreen(gameLog *history.Log, seats map[int]bots.Bot) (*models.Game, error) {
+	terminal, err := tui.OpenTerminal(os.Stdin, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	defer terminal.Close()
+	screen := tui.NewScreen(os.Stdin, os.Stdout,
+		tui.WithTileRenderer(interactions.DefaultTileRenderer(os.Stdout)),
+		tui.WithWidth(terminal.Width()))
+	game := gameLog.NewGame(models.WithObserver(models.ObserverFunc(screen.DisplayEvent)))
+
+	// The screen shows whose turn it is and the moves, so nothing else is written on it
+	err = playGame(game, seats, screen, ioutil.Discard)
+	return game, err
+}
+
+// setUpPlayers reads the players' descriptions, which are either a name or "bot:" followed by
+// a bot, and returns the names of the players and the bots that play for them
+func setUpPlayers(config models.GameConfig, specs []string) ([]string, map[int]bots.Bot, error) {
+	if err := config.ValidateNumberOfPlayers(len(specs)); err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, len(specs))
+	seats := make(map[int]bots.Bot)
+	for i, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if !strings.HasPrefix(spec, "bot:") {
+			if err := validatePlayerName(spec, names[:i]); err != nil {
+				return nil, nil, err
+			}
+			names[i] = spec
+			continue
+		}
+
+		bot, name, err := newBot(strings.TrimPrefix(spec, "bot:"), bots.DefaultMoveTime)
+		if err != nil {
+			return nil, nil, err
+		}
+		names[i] = fmt.Sprintf("%s bot #%d", name, i)
+		seats[i] = bot
+	}
+	return names, seats, nil
+}
+
+// loadConfig reads a game config from a JSON file. The fields that aren't in the file keep
+// their values from models.DefaultGameConfig.
+func loadConfig(path string) (models.GameConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return models.GameConfig{}, err
+	}
+
+	// The default config's slice and map are copied, so reading the file doesn't change them
+	config := models.DefaultGameConfig
+	config.TileColors = append([]models.TileColor{}, config.TileColors...)
+	config.PlayersToFactoriesMap = make(map[int]int, len(models.DefaultGameConfig.PlayersToFactoriesMap))
+	for players, factories := range models.DefaultGameConfig.PlayersToFactoriesMap {
+		config.PlayersToFactoriesMap[players] = factories
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return models.GameConfig{}, fmt.Errorf("%s isn't a valid game config: %w", path, err)
+	}
+	return config, config.Validate()
+}
+
+// validatePlayerName returns an error if the name is empty, or one of the other players already
+// has it
+func validatePlayerName(name string, others []string) error {
+	if name == "" || indexOf(others, name) >= 0 {
+		return fmt.Errorf("'%s' isn't a valid player, each player needs a different name", name)
+	}
+	return nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// replay shows the moves of a saved game, and what happened after each of them
+func replay(args []string) {
+	flags := newFlagSet("replay", "<savefile>", "Replays the moves of a saved game, with what happened after each of them, and shows the board at the end.")
+	numMoves := flags.Int("moves", -1, "the number of moves to replay (all of them if it's negative)")
+	step := flags.Bool("step", false, "show the board after each move, and wait for enter before the next one")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gameLog, err := loadSaveFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	moves := gameLog.Moves
+	if *numMoves >= 0 && *numMoves < len(moves) {
+		moves = moves[:*numMoves]
+	}
+
+	game := gameLog.NewGame(models.WithObserver(models.ObserverFunc(console.DisplayEvent)))
+	for i, move := range moves {
+		fmt.Printf("#%d %s: %s\n", i+1, game.CurrentPlayer().Name, move)
+		if err := game.TakeTurn(move); err != nil {
+			fmt.Printf("move #%d can't be replayed: %s\n", i+1, err)
+			os.Exit(1)
+		}
+		if *step && i < len(moves)-1 {
+			console.DisplayGameState(game)
+			if _, err := console.PromptForString("Press enter for the next move"); err != nil {
+				exitUnlessQuit(err)
+				return
+			}
+		}
+	}
+
+	console.DisplayGameState(game)
+	if game.GameOver {
+		displayFinalScores(game)
+	}
+}
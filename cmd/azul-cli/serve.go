@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/aaron-zeisler/azul/internal/lineserver"
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/server"
+)
+
+// serve hosts games over HTTP until the process is stopped. The same games can also be played
+// over the plain-text TCP protocol, if it has an address to listen on. If it has a data
+// directory, the games are saved there and picked up again when the server restarts.
+func serve(args []string) {
+	flags := newFlagSet("serve", "", "Hosts games over HTTP until it's stopped.")
+	addr := flags.String("addr", ":8080", "the address to listen on")
+	tcpAddr := flags.String("tcp", "", "the address to listen on for the plain-text TCP protocol, for example :8081")
+	dataDir := flags.String("data", "", "the directory to save the games in, so they survive a restart")
+	flags.Parse(args)
+
+	games := manager.NewManager()
+	if *dataDir != "" {
+		store, err := manager.NewFileStore(*dataDir)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer store.Close()
+
+		games = manager.NewManager(manager.WithStore(store))
+		if err := games.Restore(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+	if *tcpAddr != "" {
+		go func() {
+			fmt.Printf("AZUL TCP SERVER LISTENING ON %s ...\n", *tcpAddr)
+			if err := lineserver.NewServer(lineserver.WithManager(games)).ListenAndServe(*tcpAddr); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	fmt.Printf("AZUL SERVER LISTENING ON %s ...\n", *addr)
+	if err := http.ListenAndServe(*addr, server.NewServer(server.WithManager(games))); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
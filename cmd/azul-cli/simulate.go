@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// simulate plays many games between the same bots, and shows how each seat did
+func simulate(args []string) {
+	flags := newFlagSet("simulate", "<bot> <bot> ...", fmt.Sprintf("Plays many games between the bots, each with its own seed, and shows how each seat did. The seats take turns going first, and a tie is a win for each of the tied seats. Each bot is one of %v, or engine:<command> to run an engine.", bots.Names()))
+	games := flags.Int("games", 100, "the number of games to play")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "the seed for the first game, which goes up by one for each game after it")
+	moveTime := flags.Duration("movetime", bots.DefaultMoveTime, "how long each engine has to choose a move")
+	flags.Parse(args)
+	if err := models.DefaultGameConfig.ValidateNumberOfPlayers(flags.NArg()); err != nil || *games < 1 {
+		if err != nil {
+			fmt.Println(err)
+		}
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	players, names, err := newBots(flags.Args(), *moveTime)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer closeBots(players)
+
+	wins := make([]int, len(players))
+	scores := make([]int, len(players))
+	for i := 0; i < *games; i++ {
+		gameSeed := *seed + int64(i)
+		game, err := playBotGame(players, names, gameSeed, i%len(players), nil)
+		if err != nil {
+			fmt.Printf("game #%d (seed %d): %s\n", i, gameSeed, err)
+			os.Exit(1)
+		}
+		for seat := range players {
+			scores[seat] += game.Players[seat].Board.Score
+		}
+		for _, winner := range game.Winners() {
+			wins[winner]++
+		}
+	}
+
+	fmt.Printf("%d GAMES, SEEDS %d TO %d\n", *games, *seed, *seed+int64(*games-1))
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SEAT\tBOT\tWINS\tWIN RATE\tAVERAGE SCORE")
+	for seat, name := range names {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%.1f%%\t%.1f\n", seat, name, wins[seat],
+			100*float64(wins[seat])/float64(*games), float64(scores[seat])/float64(*games))
+	}
+	table.Flush()
+}
+
+// standing is how a bot is doing in a tournament
+type standing struct {
+	name                       string
+	played, wins, ties, losses int
+	score                      int
+}
+
+// points is 1 for each win and a half for each tie
+func (s standing) points() float64 {
+	return float64(s.wins) + float64(s.ties)/2
+}
+
+// tournament plays every pair of bots against each other, and ranks them
+func tournament(args []string) {
+	flags := newFlagSet("tournament", "<bot> <bot> ...", fmt.Sprintf("Plays every pair of bots against each other in two-player games, and ranks the bots by their points: 1 for a win, and a half for a tie. A bot that fails to make a legal move loses the game, and an engine that fails is started again for its next game. Each bot is one of %v, or engine:<command> to run an engine.", bots.Names()))
+	games := flags.Int("games", 10, "the number of games each pair of bots plays, taking turns going first")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "the seed for the first game, which goes up by one for each game after it")
+	moveTime := flags.Duration("movetime", bots.DefaultMoveTime, "how long each engine has to choose a move")
+	flags.Parse(args)
+	if flags.NArg() < 2 || *games < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	players, names, err := newBots(flags.Args(), *moveTime)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer closeBots(players)
+
+	standings := make([]standing, len(players))
+	for i, name := range names {
+		standings[i].name = name
+	}
+	gameSeed := *seed
+	for a := 0; a < len(players); a++ {
+		for b := a + 1; b < len(players); b++ {
+			pair := []int{a, b}
+			for i := 0; i < *games; i++ {
+				for _, player := range pair {
+					if players[player], err = restartFailedEngine(players[player], flags.Arg(player), *moveTime); err != nil {
+						fmt.Printf("%s couldn't be restarted, so it loses the game: %s\n", names[player], err)
+					}
+				}
+				game, err := playBotGame([]bots.Bot{players[a], players[b]}, []string{names[a], names[b]}, gameSeed, i%2, nil)
+				gameSeed++
+
+				var winners []int
+				if err != nil {
+					fmt.Printf("%s vs %s: %s\n", names[a], names[b], err)
+					winners = []int{1 - game.CurrentPlayerKey}
+				} else {
+					winners = game.Winners()
+				}
+				for seat, player := range pair {
+					standings[player].played++
+					standings[player].score += game.Players[seat].Board.Score
+				}
+				switch {
+				case len(winners) > 1:
+					standings[a].ties++
+					standings[b].ties++
+				default:
+					standings[pair[winners[0]]].wins++
+					standings[pair[1-winners[0]]].losses++
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].points() != standings[j].points() {
+			return standings[i].points() > standings[j].points()
+		}
+		return standings[i].score > standings[j].score
+	})
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "RANK\tBOT\tPLAYED\tWINS\tTIES\tLOSSES\tPOINTS\tAVERAGE SCORE")
+	for i, s := range standings {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%d\t%d\t%d\t%.1f\t%.1f\n", i+1, s.name, s.played, s.wins, s.ties, s.losses,
+			s.points(), float64(s.score)/float64(s.played))
+	}
+	table.Flush()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/history"
+	"github.com/aaron-zeisler/azul/internal/interactions"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// turn plays one move of a game that's kept in a file, so a game can be played a move at a
+// time by passing the file around, without a server. The file is the game's history.Log.
+func turn(args []string) {
+	flags := newFlagSet("turn", "<savefile>", "Shows the game in the save file, asks the current player for one move, and saves it. Start a game with -new.")
+	newPlayers := flags.String("new", "", "start a new game in the file for the players, for example alice,bob")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "the seed for a new game")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	path := flags.Arg(0)
+
+	if *newPlayers != "" {
+		if err := newSaveFile(path, strings.Split(*newPlayers, ","), *seed); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := playTurn(path); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// newSaveFile starts a game for the players in a new file
+func newSaveFile(path string, names []string, seed int64) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := models.DefaultGameConfig.ValidateNumberOfPlayers(len(names)); err != nil {
+		return err
+	}
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+		if err := validatePlayerName(names[i], names[:i]); err != nil {
+			return err
+		}
+	}
+
+	gameLog := history.NewLog(models.DefaultGameConfig, seed, names)
+	if err := writeSaveFile(path, gameLog); err != nil {
+		return err
+	}
+	fmt.Printf("The game has been saved to %s. It's %s's turn first.\n", path, names[gameLog.FirstPlayer])
+	return nil
+}
+
+// playTurn shows the game in the file, makes the current player's move, and saves it
+func playTurn(path string) error {
+	gameLog, err := loadSaveFile(path)
+	if err != nil {
+		return err
+	}
+	game, err := gameLog.Latest(models.WithObserver(models.ObserverFunc(console.DisplayEvent)))
+	if err != nil {
+		return fmt.Errorf("%s can't be replayed: %w", path, err)
+	}
+
+	console.DisplayGameState(game)
+	if game.GameOver {
+		displayFinalScores(game)
+		return nil
+	}
+
+	fmt.Printf("CURRENT PLAYER: %s (move #%d)\n", game.CurrentPlayer().Name, len(gameLog.Moves)+1)
+	for {
+		move, err := console.PromptForMove(game)
+		if errors.As(err, &interactions.QuitError{}) {
+			fmt.Println("No move was made, the file hasn't changed")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := gameLog.Record(game, move); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		break
+	}
+
+	if err := writeSaveFile(path, gameLog); err != nil {
+		return err
+	}
+	console.DisplayGameState(game)
+	if game.GameOver {
+		displayFinalScores(game)
+		return nil
+	}
+	fmt.Printf("The move has been saved to %s. It's %s's turn next.\n", path, game.CurrentPlayer().Name)
+	return nil
+}
+
+func displayFinalScores(game *models.Game) {
+	fmt.Println("GAME OVER")
+	for i := 0; i < len(game.Players); i++ {
+		fmt.Printf("%s: %d points\n", game.Players[i].Name, game.Players[i].Board.Score)
+	}
+	for _, winner := range game.Winners() {
+		fmt.Printf("%s wins!\n", game.Players[winner].Name)
+	}
+}
+
+// loadSaveFile reads the game log in a save file
+func loadSaveFile(path string) (*history.Log, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var gameLog history.Log
+	if err := json.Unmarshal(data, &gameLog); err != nil {
+		return nil, fmt.Errorf("%s isn't a saved game: %w", path, err)
+	}
+	return &gameLog, nil
+}
+
+// writeSaveFile writes the log to a temporary file next to the save file, and then replaces
+// the save file with it, so the game isn't lost if writing fails part of the way through. The
+// save file keeps its permissions, and a new one can be read by everyone, like any other file.
+func writeSaveFile(path string, gameLog *history.Log) error {
+	data, err := json.MarshalIndent(gameLog, "", "  ")
+	if err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/interactions"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// useConsole has the console read the input, until the test is over
+func useConsole(t *testing.T, input string) {
+	previous := console
+	console = interactions.NewSession(strings.NewReader(input), ioutil.Discard)
+	t.Cleanup(func() { console = previous })
+}
+
+// moveEntry types the move on one line, the way a player would
+func moveEntry(move models.Move) string {
+	source := "c"
+	if move.DrawSourceType == models.DrawSourceFactory {
+		source = fmt.Sprintf("f%d", move.FactoryNumber)
+	}
+	destination := "floor"
+	if move.PatternLineNumber != models.FloorLine {
+		destination = fmt.Sprint(move.PatternLineNumber)
+	}
+	return fmt.Sprintf("%s %s %s\n", source, move.TileColor, destination)
+}
+
+func TestNewSaveFile(t *testing.T) {
+	testCases := map[string]struct {
+		names         []string
+		expectedNames []string
+		expectedError string
+	}{
+		"Two players":       {names: []string{"alice", " bob "}, expectedNames: []string{"alice", "bob"}},
+		"Too few players":   {names: []string{"alice"}, expectedError: "player"},
+		"An empty name":     {names: []string{"alice", " "}, expectedError: "'' isn't a valid player"},
+		"The same name":     {names: []string{"alice", "bob", "alice"}, expectedError: "'alice' isn't a valid player"},
+		"Too many players":  {names: []string{"a", "b", "c", "d", "e"}, expectedError: "player"},
+		"Names with spaces": {names: []string{"alice", "alice "}, expectedError: "'alice' isn't a valid player"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			path := filepath.Join(t.TempDir(), "game.json")
+
+			err := newSaveFile(path, tc.names, 1)
+			if tc.expectedError != "" {
+				assert.So(err, should.NotBeNil)
+				assert.So(err.Error(), should.ContainSubstring, tc.expectedError)
+				_, err = os.Stat(path)
+				assert.So(os.IsNotExist(err), should.BeTrue)
+				return
+			}
+			assert.So(err, should.BeNil)
+			gameLog, err := loadSaveFile(path)
+			assert.So(err, should.BeNil)
+			assert.So(gameLog.Players, should.Resemble, tc.expectedNames)
+			info, err := os.Stat(path)
+			assert.So(err, should.BeNil)
+			assert.So(info.Mode().Perm(), should.Equal, os.FileMode(0644))
+
+			// The file is never overwritten with a new game
+			assert.So(newSaveFile(path, tc.names, 2), should.NotBeNil)
+		})
+	}
+}
+
+func TestPlayTurn(t *testing.T) {
+	assert := assertions.New(t)
+	path := filepath.Join(t.TempDir(), "game.json")
+	assert.So(newSaveFile(path, []string{"alice", "bob"}, 1), should.BeNil)
+
+	// Each turn makes one move, and the next turn picks the game up from the file
+	var expected []models.Move
+	for i := 0; i < 2; i++ {
+		gameLog, err := loadSaveFile(path)
+		assert.So(err, should.BeNil)
+		game, err := gameLog.Latest()
+		assert.So(err, should.BeNil)
+		move := game.LegalMoves()[0]
+		expected = append(expected, move)
+
+		useConsole(t, moveEntry(move))
+		assert.So(playTurn(path), should.BeNil)
+	}
+	gameLog, err := loadSaveFile(path)
+	assert.So(err, should.BeNil)
+	assert.So(gameLog.Moves, should.Resemble, expected)
+
+	// The file keeps its permissions
+	assert.So(os.Chmod(path, 0600), should.BeNil)
+	game, err := gameLog.Latest()
+	assert.So(err, should.BeNil)
+	useConsole(t, moveEntry(game.LegalMoves()[0]))
+	assert.So(playTurn(path), should.BeNil)
+	info, err := os.Stat(path)
+	assert.So(err, should.BeNil)
+	assert.So(info.Mode().Perm(), should.Equal, os.FileMode(0600))
+}
+
+func TestPlayTurn_NoMove(t *testing.T) {
+	testCases := map[string]struct {
+		input string
+	}{
+		"Quit":                          {input: "quit\n"},
+		"Nothing to read":               {input: ""},
+		"A refused move, and then quit": {input: "f9 blue 1\nc red 7\nquit\n"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			path := filepath.Join(t.TempDir(), "game.json")
+			assert.So(newSaveFile(path, []string{"alice", "bob"}, 1), should.BeNil)
+			before, err := ioutil.ReadFile(path)
+			assert.So(err, should.BeNil)
+
+			useConsole(t, tc.input)
+			assert.So(playTurn(path), should.BeNil)
+			after, err := ioutil.ReadFile(path)
+			assert.So(err, should.BeNil)
+			assert.So(string(after), should.Equal, string(before))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// validate checks that save files can be replayed, or that game configs can be played
+func validate(args []string) {
+	flags := newFlagSet("validate", "<file> ...", "Checks that each save file can be replayed to the end, or with -config, that each game config can be played.")
+	configs := flags.Bool("config", false, "the files are game configs, instead of save files")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	valid := true
+	for _, path := range flags.Args() {
+		check := validateSaveFile
+		if *configs {
+			check = validateConfigFile
+		}
+		summary, err := check(path)
+		if err != nil {
+			fmt.Printf("%s: %s\n", path, err)
+			valid = false
+			continue
+		}
+		fmt.Printf("%s: OK, %s\n", path, summary)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// validateSaveFile replays the game in the save file, and describes where the game is
+func validateSaveFile(path string) (string, error) {
+	gameLog, err := loadSaveFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := gameLog.Config.Validate(); err != nil {
+		return "", err
+	}
+	if err := gameLog.Config.ValidateNumberOfPlayers(len(gameLog.Players)); err != nil {
+		return "", err
+	}
+	if gameLog.FirstPlayer < 0 || gameLog.FirstPlayer >= len(gameLog.Players) {
+		return "", fmt.Errorf("there is no player #%d to go first", gameLog.FirstPlayer)
+	}
+
+	// The moves are replayed from the start, because the snapshots can't be checked
+	gameLog.Snapshots = nil
+	game, err := gameLog.Latest()
+	if err != nil {
+		return "", err
+	}
+	if game.GameOver {
+		return fmt.Sprintf("%d moves, the game is over", len(gameLog.Moves)), nil
+	}
+	return fmt.Sprintf("%d moves, round %d, %s's turn", len(gameLog.Moves), game.Round, game.CurrentPlayer().Name), nil
+}
+
+func validateConfigFile(path string) (string, error) {
+	config, err := loadConfig(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("for %d to %d players", config.MinNumberOfPlayers, config.MaxNumberOfPlayers), nil
+}
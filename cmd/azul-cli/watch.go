@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aaron-zeisler/azul/internal/client"
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// watch follows a game on a server, read-only, until the game ends or the server goes away
+func watch(args []string) {
+	flags := newFlagSet("watch", "<server> <game-id>", "Follows a game on a server without playing in it.")
+	moveLogLength := flags.Int("moves", 10, "the number of recent moves to show")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	stream, err := client.NewClient(flags.Arg(0)).Stream(flags.Arg(1))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer stream.Close()
+
+	var moveLog []string
+	moveCount := 0
+	for message := range stream.Messages {
+		if message.Type != manager.MessageTypeState {
+			console.DisplayEvent(message.Event)
+			if drawn, ok := message.Event.(models.TilesDrawn); ok {
+				moveCount++
+				moveLog = append(moveLog, fmt.Sprintf("#%d %s: %s", moveCount, drawn.PlayerName, drawn.Move))
+			}
+			continue
+		}
+
+		state := message.Game
+		moveCount = state.MoveCount
+		fmt.Println()
+		fmt.Printf("WATCHING GAME %s (%s)\n", state.ID, state.Status)
+		console.DisplayGameState(models.RestoreGame(state.State))
+		displayMoveLog(moveLog, *moveLogLength)
+		if state.Status == manager.GameStatusFinished {
+			return
+		}
+		if state.Status == manager.GameStatusPlaying {
+			fmt.Printf("WAITING FOR %s TO MOVE ...\n", state.Seats[state.CurrentPlayer].Name)
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("The server closed the stream")
+}
+
+// displayMoveLog prints the last few moves of the game
+func displayMoveLog(moveLog []string, length int) {
+	if len(moveLog) == 0 {
+		return
+	}
+	if len(moveLog) > length {
+		moveLog = moveLog[len(moveLog)-length:]
+	}
+
+	fmt.Println()
+	fmt.Println("RECENT MOVES:")
+	for _, move := range moveLog {
+		fmt.Printf("  %s\n", move)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/aaron-zeisler/azul
 
 go 1.15
 
-require github.com/smartystreets/assertions v1.2.0
+require (
+	github.com/gorilla/websocket v1.4.2
+	github.com/smartystreets/assertions v1.2.0
+)
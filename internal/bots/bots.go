@@ -0,0 +1,130 @@
+package bots
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Bot chooses moves for a player. ChooseMove is only called when it's the bot's turn, and the
+// move it returns must be one of the game's legal moves.
+type Bot interface {
+	ChooseMove(game *models.Game) (models.Move, error)
+}
+
+// newBotFuncs maps the name of each kind of bot to a function that creates one
+var newBotFuncs = map[string]func() Bot{
+	"random": func() Bot { return NewRandomBot(time.Now().UnixNano()) },
+	"greedy": func() Bot { return GreedyBot{} },
+}
+
+// New creates a bot by name, for example "random" or "greedy"
+func New(name string) (Bot, error) {
+	newBot, ok := newBotFuncs[name]
+	if !ok {
+		return nil, models.InvalidActionError{Message: fmt.Sprintf("There is no '%s' bot, please choose one of %v", name, Names())}
+	}
+	return newBot(), nil
+}
+
+// Names returns the names of the bots New can create
+func Names() []string {
+	names := make([]string, 0, len(newBotFuncs))
+	for name := range newBotFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type noLegalMovesError struct{}
+
+func (e noLegalMovesError) Error() string {
+	return "There are no legal moves"
+}
+
+// RandomBot picks any legal move
+type RandomBot struct {
+	random *rand.Rand
+}
+
+func NewRandomBot(seed int64) *RandomBot {
+	return &RandomBot{random: rand.New(rand.NewSource(seed))}
+}
+
+func (b *RandomBot) ChooseMove(game *models.Game) (models.Move, error) {
+	moves := game.LegalMoves()
+	if len(moves) == 0 {
+		return models.Move{}, noLegalMovesError{}
+	}
+	return moves[b.random.Intn(len(moves))], nil
+}
+
+// GreedyBot picks the move that looks best for this turn alone: it fills as much of a pattern
+// line as it can, likes completing lines that score well on the wall, and avoids the floor.
+type GreedyBot struct{}
+
+func (b GreedyBot) ChooseMove(game *models.Game) (models.Move, error) {
+	moves := game.LegalMoves()
+	if len(moves) == 0 {
+		return models.Move{}, noLegalMovesError{}
+	}
+
+	best, bestValue := moves[0], evaluateMove(game, moves[0])
+	for _, move := range moves[1:] {
+		if value := evaluateMove(game, move); value > bestValue {
+			best, bestValue = move, value
+		}
+	}
+	return best, nil
+}
+
+// evaluateMove estimates how many points the move is worth to the current player by the end
+// of the round
+func evaluateMove(game *models.Game, move models.Move) int {
+	board := game.CurrentPlayer().Board
+
+	source := game.CenterOfTheTable
+	if move.DrawSourceType == models.DrawSourceFactory {
+		source = game.Factories[move.FactoryNumber].TileCollection
+	}
+	var drawn, floorTiles int
+	for _, tile := range source.Tiles {
+		if tile.Color == move.TileColor {
+			drawn++
+		} else if tile.Color == models.FirstPlayerTile {
+			floorTiles++
+		}
+	}
+
+	var value int
+	if move.PatternLineNumber == models.FloorLine {
+		floorTiles += drawn
+	} else {
+		line := board.PatternLines[move.PatternLineNumber]
+		space := cap(line) - len(line)
+		placed := drawn
+		if placed > space {
+			placed = space
+		}
+		floorTiles += drawn - placed
+		value += placed
+
+		if placed == space {
+			for col, wallSpace := range board.Wall[move.PatternLineNumber] {
+				if wallSpace.Color == move.TileColor {
+					value += 2 * board.ScoreTile(models.WallCoordinate{Row: move.PatternLineNumber, Col: col}).Score
+				}
+			}
+		}
+	}
+
+	for i := len(board.Floor); i < len(board.Floor)+floorTiles && i < models.NumFloorSpaces; i++ {
+		value += 2 * models.FloorScoreModifiers[i]
+	}
+
+	return value
+}
@@ -0,0 +1,77 @@
+package bots
+
+import (
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func newTestGame() *models.Game {
+	return models.NewGame(
+		models.WithSeed(1),
+		models.WithPlayers(map[int]models.Player{
+			0: models.NewPlayer("alice", models.FirstPlayer()),
+			1: models.NewPlayer("bob"),
+		}))
+}
+
+func TestBots_PlayFullGame(t *testing.T) {
+	testCases := map[string]struct {
+		players []Bot
+	}{
+		"Random against random": {players: []Bot{NewRandomBot(1), NewRandomBot(2)}},
+		"Greedy against random": {players: []Bot{GreedyBot{}, NewRandomBot(3)}},
+		"Greedy against greedy": {players: []Bot{GreedyBot{}, GreedyBot{}}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+
+			for turns := 0; !game.GameOver; turns++ {
+				if turns > 1000 {
+					t.Fatal("the game didn't end")
+				}
+				move, err := tc.players[game.CurrentPlayerKey].ChooseMove(game)
+				assert.So(err, should.BeNil)
+				assert.So(game.TakeTurn(move), should.BeNil)
+			}
+
+			_, err := tc.players[0].ChooseMove(game)
+			assert.So(err, should.NotBeNil)
+		})
+	}
+}
+
+func TestGreedyBot_ChooseMove(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	for _, factory := range game.Factories {
+		factory.Tiles = []models.Tile{}
+	}
+	game.Factories[0].Tiles = []models.Tile{{Color: models.Red}, {Color: models.Red}, {Color: models.Blue}, {Color: models.White}}
+	game.Factories[1].Tiles = []models.Tile{{Color: models.Black}, {Color: models.Black}, {Color: models.Black}, {Color: models.Black}}
+
+	move, err := GreedyBot{}.ChooseMove(game)
+
+	// The four blacks complete line #3 without any going to the floor, which beats completing line #1 with the reds
+	assert.So(err, should.BeNil)
+	assert.So(move, should.Resemble, models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Black, PatternLineNumber: 3})
+}
+
+func TestNew(t *testing.T) {
+	assert := assertions.New(t)
+
+	for _, name := range Names() {
+		bot, err := New(name)
+		assert.So(err, should.BeNil)
+		assert.So(bot, should.NotBeNil)
+	}
+
+	_, err := New("grandmaster")
+	assert.So(err, should.NotBeNil)
+}
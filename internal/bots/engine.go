@@ -0,0 +1,307 @@
+package bots
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// An engine is a bot that runs in its own process, so it can be written in any language. The
+// harness talks to it over the engine's stdin and stdout, one command per line, in the spirit
+// of chess's UCI protocol:
+//
+//	HARNESS                       ENGINE
+//	azul                          id name <name>    (optional)
+//	                              azulok
+//	position <game as JSON>
+//	legal <move> <move> ...
+//	go movetime <milliseconds>    bestmove <move>
+//	quit
+//
+// The position is a public models.GameSnapshot on a single line (see GameSnapshot.Public), so
+// the engine doesn't get the seed or the bag that would tell it which tiles come next. The
+// engine plays for its CurrentPlayerKey. The legal moves are listed so engines don't have to
+// work them out for themselves. Moves are written in notation, for example "F2:blue>L3". The
+// engine can write "info <anything>" lines while it thinks, and they're ignored.
+//
+// The harness doesn't wait for an engine that runs out of time, and it doesn't accept a move
+// that isn't legal. Either way ChooseMove returns an error, the engine's process is stopped,
+// and the engine isn't used again. A new Engine has to be started to play on.
+
+// EngineTimeoutError is returned when an engine doesn't answer in time
+type EngineTimeoutError struct {
+	Message string
+}
+
+func (e EngineTimeoutError) Error() string {
+	return e.Message
+}
+
+// EngineReplyError is returned when an engine answers with something other than a legal move,
+// or stops running
+type EngineReplyError struct {
+	Message string
+}
+
+func (e EngineReplyError) Error() string {
+	return e.Message
+}
+
+// DefaultMoveTime is how long an engine has to choose a move, if WithMoveTime isn't used
+const DefaultMoveTime = 5 * time.Second
+
+// engineStartTimeout is how long an engine has to start up and answer "azulok"
+const engineStartTimeout = 10 * time.Second
+
+// Engine is a bot that runs in a separate process
+type Engine struct {
+	Name string
+
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan string
+	moveTime time.Duration
+	// err is set once the engine has failed, and it's returned by every later call
+	err      error
+	stopOnce sync.Once
+}
+
+type NewEngineOption func(e *Engine)
+
+// WithMoveTime sets how long the engine has to choose each move
+func WithMoveTime(moveTime time.Duration) NewEngineOption {
+	return func(e *Engine) {
+		e.moveTime = moveTime
+	}
+}
+
+// NewEngine starts the engine's process and waits for it to be ready. The engine's name is
+// the one it gives in its "id name" line, or the command if it doesn't give one.
+func NewEngine(command string, args []string, opts ...NewEngineOption) (*Engine, error) {
+	e := &Engine{
+		Name:     command,
+		cmd:      exec.Command(command, args...),
+		lines:    make(chan string),
+		moveTime: DefaultMoveTime,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	stdin, err := e.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := e.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	e.stdin = stdin
+	if err := e.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start the engine '%s': %w", command, err)
+	}
+
+	go func() {
+		defer close(e.lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			e.lines <- scanner.Text()
+		}
+	}()
+
+	if err := e.send("azul"); err != nil {
+		return nil, err
+	}
+	err = e.readUntil(engineStartTimeout, func(line string) bool {
+		if name := strings.TrimPrefix(line, "id name "); name != line {
+			e.Name = strings.TrimSpace(name)
+		}
+		return line == "azulok"
+	})
+	if err != nil {
+		e.Close()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// ChooseMove sends the game to the engine and waits for its move
+func (e *Engine) ChooseMove(game *models.Game) (models.Move, error) {
+	if e.err != nil {
+		return models.Move{}, e.err
+	}
+
+	position, err := json.Marshal(game.Snapshot().Public())
+	if err != nil {
+		return models.Move{}, err
+	}
+	legalMoves := game.LegalMoves()
+	notations := make([]string, len(legalMoves))
+	for i, move := range legalMoves {
+		notations[i] = move.String()
+	}
+
+	if err := e.send(
+		"position "+string(position),
+		"legal "+strings.Join(notations, " "),
+		fmt.Sprintf("go movetime %d", e.moveTime.Milliseconds()),
+	); err != nil {
+		return models.Move{}, err
+	}
+
+	var reply string
+	err = e.readUntil(e.moveTime, func(line string) bool {
+		if strings.HasPrefix(line, "bestmove") {
+			reply = strings.TrimSpace(strings.TrimPrefix(line, "bestmove"))
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return models.Move{}, err
+	}
+
+	move, err := models.ParseMove(reply)
+	if err != nil {
+		return models.Move{}, e.fail(EngineReplyError{Message: fmt.Sprintf("%s replied with an invalid move: %s", e.Name, err)})
+	}
+	for _, legalMove := range legalMoves {
+		if move == legalMove {
+			return move, nil
+		}
+	}
+	return models.Move{}, e.fail(EngineReplyError{Message: fmt.Sprintf("%s replied with '%s', which isn't a legal move", e.Name, reply)})
+}
+
+// Err returns the error that stopped the engine, or nil if it can still play
+func (e *Engine) Err() error {
+	return e.err
+}
+
+// Close asks the engine to quit, and stops its process if it doesn't. It can be called more
+// than once, and after the engine has failed.
+func (e *Engine) Close() error {
+	if e.err == nil {
+		e.send("quit")
+	}
+	e.stop(time.Second)
+
+	if e.err == nil {
+		e.err = EngineReplyError{Message: fmt.Sprintf("%s has been closed", e.Name)}
+	}
+	return nil
+}
+
+// stop waits for the engine's process to exit, and kills it if it's still running after the
+// grace period. Anything the engine still writes is thrown away, so neither the engine nor
+// the goroutine reading its stdout is left blocked. Only the first call does anything.
+func (e *Engine) stop(grace time.Duration) {
+	e.stopOnce.Do(func() {
+		e.stdin.Close()
+		go func() {
+			for range e.lines {
+			}
+		}()
+
+		exited := make(chan struct{})
+		go func() {
+			e.cmd.Wait()
+			close(exited)
+		}()
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-exited:
+		case <-timer.C:
+			e.cmd.Process.Kill()
+			<-exited
+		}
+	})
+}
+
+func (e *Engine) send(lines ...string) error {
+	for _, line := range lines {
+		if _, err := io.WriteString(e.stdin, line+"\n"); err != nil {
+			return e.fail(EngineReplyError{Message: fmt.Sprintf("failed to write to %s: %s", e.Name, err)})
+		}
+	}
+	return nil
+}
+
+// readUntil reads the engine's lines until done returns true for one of them. Lines that
+// done doesn't want, like "info" lines, are skipped.
+func (e *Engine) readUntil(timeout time.Duration, done func(line string) bool) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return e.fail(EngineReplyError{Message: fmt.Sprintf("%s stopped running", e.Name)})
+			}
+			if done(strings.TrimSpace(line)) {
+				return nil
+			}
+		case <-timer.C:
+			return e.fail(EngineTimeoutError{Message: fmt.Sprintf("%s didn't answer within %s", e.Name, timeout)})
+		}
+	}
+}
+
+// fail stops the engine after an error, because its replies can't be trusted anymore
+func (e *Engine) fail(err error) error {
+	if e.err == nil {
+		e.err = err
+		e.stop(0)
+	}
+	return err
+}
+
+// ServeEngine runs the bot as an engine, reading the harness's commands from r and writing
+// the replies to w, until the harness quits or r ends. It's how the bots in this package can
+// be used wherever an engine is expected, and it shows how an engine works.
+func ServeEngine(name string, bot Bot, r io.Reader, w io.Writer) error {
+	var game *models.Game
+
+	scanner := bufio.NewScanner(r)
+	// The position is a whole game on one line
+	scanner.Buffer(make([]byte, 4096), 1024*1024)
+	for scanner.Scan() {
+		command, args := scanner.Text(), ""
+		if i := strings.IndexByte(command, ' '); i >= 0 {
+			command, args = command[:i], command[i+1:]
+		}
+
+		switch command {
+		case "azul":
+			fmt.Fprintf(w, "id name %s\nazulok\n", name)
+		case "position":
+			var snapshot models.GameSnapshot
+			if err := json.Unmarshal([]byte(args), &snapshot); err != nil {
+				return fmt.Errorf("the position isn't valid: %w", err)
+			}
+			game = models.RestoreGame(snapshot)
+		case "go":
+			if game == nil {
+				return fmt.Errorf("'go' was sent before the position")
+			}
+			move, err := bot.ChooseMove(game)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "bestmove %s\n", move)
+		case "quit":
+			return nil
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,127 @@
+package bots
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// engineHelperEnv makes the test binary run as an engine instead of running the tests
+const engineHelperEnv = "AZUL_TEST_ENGINE"
+
+// fixedBot always chooses the same move, whether it's legal or not
+type fixedBot struct {
+	move models.Move
+}
+
+func (b fixedBot) ChooseMove(game *models.Game) (models.Move, error) {
+	return b.move, nil
+}
+
+// slowBot takes longer than any test is willing to wait
+type slowBot struct{}
+
+func (b slowBot) ChooseMove(game *models.Game) (models.Move, error) {
+	time.Sleep(time.Minute)
+	return models.Move{}, nil
+}
+
+// TestEngineHelperProcess isn't a real test. It's run as a separate process by startTestEngine.
+func TestEngineHelperProcess(t *testing.T) {
+	var bot Bot
+	switch os.Getenv(engineHelperEnv) {
+	case "":
+		return
+	case "greedy":
+		bot = GreedyBot{}
+	case "illegal":
+		bot = fixedBot{move: models.Move{DrawSourceType: models.DrawSourceCenter, TileColor: models.Blue}}
+	case "slow":
+		bot = slowBot{}
+	}
+
+	if err := ServeEngine("test "+os.Getenv(engineHelperEnv), bot, os.Stdin, os.Stdout); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func startTestEngine(t *testing.T, kind string) *Engine {
+	os.Setenv(engineHelperEnv, kind)
+	defer os.Unsetenv(engineHelperEnv)
+
+	engine, err := NewEngine(os.Args[0], []string{"-test.run=TestEngineHelperProcess"}, WithMoveTime(2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine
+}
+
+func TestEngine_PlayFullGame(t *testing.T) {
+	assert := assertions.New(t)
+	engine := startTestEngine(t, "greedy")
+	defer engine.Close()
+	assert.So(engine.Name, should.Equal, "test greedy")
+
+	game := newTestGame()
+	players := map[int]Bot{0: engine, 1: NewRandomBot(1)}
+	for turns := 0; !game.GameOver && turns < 500; turns++ {
+		move, err := players[game.CurrentPlayerKey].ChooseMove(game)
+		assert.So(err, should.BeNil)
+		assert.So(game.TakeTurn(move), should.BeNil)
+	}
+	assert.So(game.GameOver, should.BeTrue)
+}
+
+func TestEngine_ChooseMove(t *testing.T) {
+	testCases := map[string]struct {
+		engine        string
+		moveTime      time.Duration
+		expectedError error
+	}{
+		"The engine's move is checked against the game": {
+			engine:        "greedy",
+			expectedError: nil,
+		},
+		"Error case: an illegal move is rejected": {
+			engine:        "illegal",
+			expectedError: EngineReplyError{Message: "test illegal replied with 'C:blue>L0', which isn't a legal move"},
+		},
+		"Error case: the engine runs out of time": {
+			engine:        "slow",
+			moveTime:      100 * time.Millisecond,
+			expectedError: EngineTimeoutError{Message: "test slow didn't answer within 100ms"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			engine := startTestEngine(t, tc.engine)
+			defer engine.Close()
+			if tc.moveTime != 0 {
+				engine.moveTime = tc.moveTime
+			}
+
+			game := newTestGame()
+			move, err := engine.ChooseMove(game)
+
+			assert.So(err, should.Resemble, tc.expectedError)
+			if tc.expectedError == nil {
+				expected, _ := GreedyBot{}.ChooseMove(game)
+				assert.So(move, should.Resemble, expected)
+			} else {
+				// The engine isn't trusted after an error, and its process has been stopped
+				_, err := engine.ChooseMove(game)
+				assert.So(err, should.Resemble, tc.expectedError)
+				assert.So(engine.Err(), should.Resemble, tc.expectedError)
+				assert.So(engine.cmd.ProcessState, should.NotBeNil)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+	"github.com/aaron-zeisler/azul/internal/server"
+)
+
+// Client talks to a game server started with `azul-cli serve`
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type NewClientOption func(c *Client)
+
+// WithHTTPClient sets the HTTP client used to talk to the server. http.DefaultClient is used
+// if it isn't set.
+func WithHTTPClient(httpClient *http.Client) NewClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient returns a client for the server at baseURL. The scheme is optional, and
+// "http://" is used if it's missing.
+func NewClient(baseURL string, opts ...NewClientOption) *Client {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ServerError is returned when the server responds with an error
+type ServerError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e ServerError) Error() string {
+	return e.Message
+}
+
+// Game returns the state of the game
+func (c *Client) Game(id string) (manager.GameState, error) {
+	var state manager.GameState
+	err := c.do(http.MethodGet, "/games/"+id, "", nil, &state)
+	return state, err
+}
+
+// JoinGame claims the seat for a player. The token that's returned is needed to make the
+// seat's moves.
+func (c *Client) JoinGame(id string, seat int, name string) (server.GameTokenResponse, error) {
+	var response server.GameTokenResponse
+	err := c.do(http.MethodPost, fmt.Sprintf("/games/%s/seats/%d", id, seat), "", server.JoinGameRequest{Name: name}, &response)
+	return response, err
+}
+
+// MakeMove makes a move for the seat that the token belongs to
+func (c *Client) MakeMove(id string, token string, move models.Move) (manager.GameState, error) {
+	var state manager.GameState
+	err := c.do(http.MethodPost, "/games/"+id+"/moves", token, server.MakeMoveRequest{Move: move.String()}, &state)
+	return state, err
+}
+
+// do sends the request body as JSON, and decodes the JSON response into response. The token
+// is sent in the Authorization header if it isn't empty.
+func (c *Client) do(method string, path string, token string, body interface{}, response interface{}) error {
+	var requestBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, c.baseURL+path, requestBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+
+	var errorResponse server.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil || errorResponse.Error == "" {
+		return ServerError{StatusCode: resp.StatusCode, Message: resp.Status}
+	}
+	return ServerError{StatusCode: resp.StatusCode, Message: errorResponse.Error}
+}
+
+// Stream is a live feed of a game's events and state, read from the server's stream endpoint
+type Stream struct {
+	// Messages receives the messages in the order the server sends them. It's closed when the
+	// stream ends, and then Err returns the reason.
+	Messages <-chan manager.Message
+
+	body      io.Closer
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// Close stops the stream
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.body.Close()
+	})
+}
+
+// Err returns the error that ended the stream, if there was one. It must only be called after
+// Messages has been closed.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// maxEventSize is the longest line of a Server-Sent Event the stream will read. A game's
+// state is a few kilobytes, so this leaves plenty of room.
+const maxEventSize = 1024 * 1024
+
+// Stream starts following the game. The first message is always the current state of the game.
+func (c *Client) Stream(id string) (*Stream, error) {
+	resp, err := c.httpClient.Get(c.baseURL + "/games/" + id + "/stream")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	messages := make(chan manager.Message)
+	stream := &Stream{Messages: messages, body: resp.Body, done: make(chan struct{})}
+	go func() {
+		defer close(messages)
+		stream.err = readServerSentEvents(resp.Body, messages, stream.done)
+	}()
+
+	return stream, nil
+}
+
+// readServerSentEvents decodes the data of each event into a message, until the body ends.
+// The event names aren't needed, because the messages carry their own type.
+func readServerSentEvents(body io.Reader, messages chan<- manager.Message, done <-chan struct{}) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 4096), maxEventSize)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var message manager.Message
+			if err := json.Unmarshal([]byte(data.String()), &message); err != nil {
+				return fmt.Errorf("the server sent an invalid message: %w", err)
+			}
+			select {
+			case messages <- message:
+			case <-done:
+				return nil
+			}
+			data.Reset()
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteString("\n")
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	select {
+	case <-done:
+		// Reading fails once the stream has been closed, but that isn't an error
+		return nil
+	default:
+		return scanner.Err()
+	}
+}
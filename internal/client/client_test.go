@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+	"github.com/aaron-zeisler/azul/internal/server"
+)
+
+// post sends a request straight to the server, and decodes the JSON response into response
+func post(t *testing.T, url string, token string, body interface{}, response interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		t.Fatalf("POST %s failed: %s", url, resp.Status)
+	}
+	if response != nil {
+		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func nextMessage(t *testing.T, stream *Stream) manager.Message {
+	select {
+	case message, ok := <-stream.Messages:
+		if !ok {
+			t.Fatalf("the stream ended: %v", stream.Err())
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		return manager.Message{}
+	}
+}
+
+func TestClient_Game(t *testing.T) {
+	assert := assertions.New(t)
+	httpServer := httptest.NewServer(server.NewServer())
+	defer httpServer.Close()
+
+	var created server.GameTokenResponse
+	post(t, httpServer.URL+"/games", "", server.CreateGameRequest{Players: 2}, &created)
+
+	c := NewClient(httpServer.URL)
+	state, err := c.Game(created.Game.ID)
+	assert.So(err, should.BeNil)
+	assert.So(state.ID, should.Equal, created.Game.ID)
+	assert.So(state.Status, should.Equal, manager.GameStatusWaiting)
+
+	_, err = c.Game("missing")
+	assert.So(err, should.Resemble, ServerError{StatusCode: http.StatusNotFound, Message: "game 'missing' was not found"})
+}
+
+func TestClient_Stream(t *testing.T) {
+	assert := assertions.New(t)
+	httpServer := httptest.NewServer(server.NewServer())
+	defer httpServer.Close()
+
+	seed := int64(1)
+	var created server.GameTokenResponse
+	post(t, httpServer.URL+"/games", "", server.CreateGameRequest{Players: 2, Seed: &seed}, &created)
+
+	stream, err := NewClient(httpServer.URL).Stream(created.Game.ID)
+	assert.So(err, should.BeNil)
+	defer stream.Close()
+
+	message := nextMessage(t, stream)
+	assert.So(message.Type, should.Equal, manager.MessageTypeState)
+	assert.So(message.Game.Status, should.Equal, manager.GameStatusWaiting)
+
+	c := NewClient(httpServer.URL)
+	alice, err := c.JoinGame(created.Game.ID, 0, "alice")
+	assert.So(err, should.BeNil)
+	bob, err := c.JoinGame(created.Game.ID, 1, "bob")
+	assert.So(err, should.BeNil)
+	assert.So(nextMessage(t, stream).Game.Seats[0].Name, should.Equal, "alice")
+	assert.So(nextMessage(t, stream).Game.Status, should.Equal, manager.GameStatusPlaying)
+
+	legalMoves := models.RestoreGame(bob.Game.State).LegalMoves()
+	_, err = c.MakeMove(created.Game.ID, bob.Token, legalMoves[0])
+	assert.So(err.(ServerError).StatusCode, should.Equal, http.StatusForbidden)
+	_, err = c.MakeMove(created.Game.ID, alice.Token, models.Move{DrawSourceType: models.DrawSourceCenter, TileColor: models.Blue})
+	assert.So(err.(ServerError).StatusCode, should.Equal, http.StatusBadRequest)
+	state, err := c.MakeMove(created.Game.ID, alice.Token, legalMoves[0])
+	assert.So(err, should.BeNil)
+	assert.So(state.MoveCount, should.Equal, 1)
+
+	// The events are decoded into their own types
+	message = nextMessage(t, stream)
+	drawn, ok := message.Event.(models.TilesDrawn)
+	assert.So(ok, should.BeTrue)
+	assert.So(drawn.PlayerName, should.Equal, "alice")
+	assert.So(drawn.Move, should.Resemble, legalMoves[0])
+
+	for message.Type != manager.MessageTypeState {
+		message = nextMessage(t, stream)
+	}
+	assert.So(message.Game.MoveCount, should.Equal, 1)
+
+	stream.Close()
+	for range stream.Messages {
+	}
+	assert.So(stream.Err(), should.BeNil)
+}
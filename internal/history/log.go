@@ -0,0 +1,154 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// DefaultSnapshotInterval is how many moves are recorded between snapshots
+const DefaultSnapshotInterval = 20
+
+// Log is the complete record of a game. The game can be rebuilt at any point by replaying
+// the moves from the initial config, seed and players. Snapshots of the game are taken
+// periodically so that a long game doesn't have to be replayed from the beginning.
+type Log struct {
+	Config           models.GameConfig
+	Seed             int64
+	Players          []string
+	FirstPlayer      int
+	Moves            []models.Move
+	Snapshots        []Snapshot
+	SnapshotInterval int
+}
+
+// Snapshot is the state of the game after the first MoveNumber moves of the log
+type Snapshot struct {
+	MoveNumber int
+	State      models.GameSnapshot
+}
+
+func NewLog(config models.GameConfig, seed int64, players []string, opts ...NewLogOption) *Log {
+	l := &Log{
+		Config:           config,
+		Seed:             seed,
+		Players:          players,
+		Moves:            make([]models.Move, 0),
+		Snapshots:        make([]Snapshot, 0),
+		SnapshotInterval: DefaultSnapshotInterval,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+type NewLogOption func(l *Log)
+
+func WithFirstPlayer(firstPlayer int) NewLogOption {
+	return func(l *Log) {
+		l.FirstPlayer = firstPlayer
+	}
+}
+
+func WithSnapshotInterval(interval int) NewLogOption {
+	return func(l *Log) {
+		l.SnapshotInterval = interval
+	}
+}
+
+// NewGame creates the game in its initial state, before any moves have been made
+func (l *Log) NewGame(opts ...models.NewGameOption) *models.Game {
+	players := make(map[int]models.Player, len(l.Players))
+	for i, name := range l.Players {
+		var playerOpts []models.NewPlayerOption
+		if i == l.FirstPlayer {
+			playerOpts = append(playerOpts, models.FirstPlayer())
+		}
+		players[i] = models.NewPlayer(name, playerOpts...)
+	}
+
+	opts = append([]models.NewGameOption{
+		models.WithConfig(l.Config),
+		models.WithSeed(l.Seed),
+		models.WithPlayers(players),
+	}, opts...)
+
+	return models.NewGame(opts...)
+}
+
+// Record makes the move in the game and appends it to the log. The game must be the one
+// this log describes, in the state after the last recorded move.
+func (l *Log) Record(game *models.Game, move models.Move) error {
+	if err := game.TakeTurn(move); err != nil {
+		return err
+	}
+
+	l.Moves = append(l.Moves, move)
+	if l.SnapshotInterval > 0 && len(l.Moves)%l.SnapshotInterval == 0 {
+		l.Snapshots = append(l.Snapshots, Snapshot{MoveNumber: len(l.Moves), State: game.Snapshot()})
+	}
+
+	return nil
+}
+
+// Rebuild recreates the game as it was after the first moveNumber moves. It starts from the
+// latest snapshot at or before that point, and replays the moves that follow it.
+func (l *Log) Rebuild(moveNumber int, opts ...models.NewGameOption) (*models.Game, error) {
+	if moveNumber < 0 || moveNumber > len(l.Moves) {
+		return nil, fmt.Errorf("the log has %d moves, can't rebuild the game after move %d", len(l.Moves), moveNumber)
+	}
+
+	var game *models.Game
+	start := 0
+	for i := len(l.Snapshots) - 1; i >= 0; i-- {
+		if l.Snapshots[i].MoveNumber <= moveNumber {
+			game = models.RestoreGame(l.Snapshots[i].State)
+			start = l.Snapshots[i].MoveNumber
+			break
+		}
+	}
+	if game == nil {
+		game = l.NewGame()
+	}
+
+	for i := start; i < moveNumber; i++ {
+		if err := game.TakeTurn(l.Moves[i]); err != nil {
+			return nil, fmt.Errorf("failed to replay move #%d: %w", i, err)
+		}
+	}
+
+	for _, opt := range opts {
+		opt(game)
+	}
+
+	return game, nil
+}
+
+// Latest recreates the game after all the moves in the log
+func (l *Log) Latest(opts ...models.NewGameOption) (*models.Game, error) {
+	return l.Rebuild(len(l.Moves), opts...)
+}
+
+// Fork returns a new log that starts like this one, but only contains the first moveNumber
+// moves. Moves recorded on the fork don't affect the original log.
+func (l *Log) Fork(moveNumber int) (*Log, error) {
+	if moveNumber < 0 || moveNumber > len(l.Moves) {
+		return nil, fmt.Errorf("the log has %d moves, can't fork the game after move %d", len(l.Moves), moveNumber)
+	}
+
+	fork := NewLog(l.Config, l.Seed, append([]string{}, l.Players...),
+		WithFirstPlayer(l.FirstPlayer),
+		WithSnapshotInterval(l.SnapshotInterval))
+
+	fork.Moves = append(fork.Moves, l.Moves[:moveNumber]...)
+	for _, snapshot := range l.Snapshots {
+		if snapshot.MoveNumber <= moveNumber {
+			fork.Snapshots = append(fork.Snapshots, snapshot)
+		}
+	}
+
+	return fork, nil
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// playRandomGame records random legal moves until the game is over, and returns the
+// snapshot of the game after each move
+func playRandomGame(t *testing.T, l *Log, seed int64) []models.GameSnapshot {
+	r := rand.New(rand.NewSource(seed))
+	game := l.NewGame()
+	states := []models.GameSnapshot{game.Snapshot()}
+
+	for !game.GameOver {
+		moves := game.LegalMoves()
+		if len(moves) == 0 {
+			t.Fatalf("no legal moves after %d moves", len(l.Moves))
+		}
+		if err := l.Record(game, moves[r.Intn(len(moves))]); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, game.Snapshot())
+	}
+
+	return states
+}
+
+func TestLog_Rebuild(t *testing.T) {
+	testCases := map[string]struct {
+		players          []string
+		snapshotInterval int
+	}{
+		"Two players, no snapshots": {
+			players:          []string{"alice", "bob"},
+			snapshotInterval: 0,
+		},
+		"Three players, a snapshot every 7 moves": {
+			players:          []string{"alice", "bob", "carol"},
+			snapshotInterval: 7,
+		},
+		"Four players, a snapshot every move": {
+			players:          []string{"alice", "bob", "carol", "dave"},
+			snapshotInterval: 1,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+
+			l := NewLog(models.DefaultGameConfig, 42, tc.players, WithSnapshotInterval(tc.snapshotInterval))
+			states := playRandomGame(t, l, 7)
+
+			for moveNumber, expected := range states {
+				game, err := l.Rebuild(moveNumber)
+				assert.So(err, should.BeNil)
+				assert.So(game.Snapshot(), should.Resemble, expected)
+			}
+
+			_, err := l.Rebuild(len(l.Moves) + 1)
+			assert.So(err, should.NotBeNil)
+		})
+	}
+}
+
+func TestLog_JSON(t *testing.T) {
+	assert := assertions.New(t)
+
+	l := NewLog(models.DefaultGameConfig, 3, []string{"alice", "bob"}, WithFirstPlayer(1), WithSnapshotInterval(10))
+	states := playRandomGame(t, l, 3)
+
+	data, err := json.Marshal(l)
+	assert.So(err, should.BeNil)
+
+	var decoded Log
+	assert.So(json.Unmarshal(data, &decoded), should.BeNil)
+
+	game, err := decoded.Latest()
+	assert.So(err, should.BeNil)
+	assert.So(game.Snapshot(), should.Resemble, states[len(states)-1])
+	assert.So(game.GameOver, should.BeTrue)
+
+	// Replaying from a decoded snapshot has to work just like replaying from the start
+	game, err = decoded.Rebuild(15)
+	assert.So(err, should.BeNil)
+	assert.So(game.Snapshot(), should.Resemble, states[15])
+}
+
+func TestLog_Fork(t *testing.T) {
+	assert := assertions.New(t)
+
+	l := NewLog(models.DefaultGameConfig, 5, []string{"alice", "bob"}, WithSnapshotInterval(4))
+	states := playRandomGame(t, l, 5)
+	originalMoves := len(l.Moves)
+
+	fork, err := l.Fork(10)
+	assert.So(err, should.BeNil)
+	assert.So(len(fork.Moves), should.Equal, 10)
+
+	game, err := fork.Latest()
+	assert.So(err, should.BeNil)
+	assert.So(game.Snapshot(), should.Resemble, states[10])
+
+	// Explore an alternative to the original 11th move
+	moves := game.LegalMoves()
+	alternative := moves[len(moves)-1]
+	if alternative == l.Moves[10] {
+		alternative = moves[0]
+	}
+	assert.So(fork.Record(game, alternative), should.BeNil)
+
+	assert.So(len(fork.Moves), should.Equal, 11)
+	assert.So(len(l.Moves), should.Equal, originalMoves)
+	assert.So(l.Moves[10], should.NotResemble, alternative)
+}
@@ -3,6 +3,7 @@ package interactions
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -10,14 +11,107 @@ import (
 	"github.com/aaron-zeisler/azul/internal/models"
 )
 
+// Session is a conversation with a player: the prompts read the player's answers from one
+// stream, and the prompts and the game are written to another. The streams can be a terminal,
+// a script, or a network connection.
+type Session struct {
+	reader   *bufio.Reader
+	writer   io.Writer
+	renderer TileRenderer
+
+	// legendShown is set once the renderer's legend has been shown with the game
+	legendShown bool
+
+	// round collects the scoring of each player's board until the round is over
+	round map[int]*playerRound
+
+	// The boards are arranged in the layout, across the width. A width of 0 means there's no
+	// limit to fit in, and the boards are printed one under the other.
+	layout Layout
+	width  func() int
+}
+
+type NewSessionOption func(s *Session)
+
+// WithLayout sets how the players' boards are arranged. It's LayoutColumns if it isn't set.
+func WithLayout(layout Layout) NewSessionOption {
+	return func(s *Session) {
+		s.layout = layout
+	}
+}
+
+// WithWidth sets the number of columns the game is printed in
+func WithWidth(width int) NewSessionOption {
+	return func(s *Session) {
+		s.width = func() int { return width }
+	}
+}
+
+// WithTerminalWidth prints the game in the width of the terminal that out writes to, which is
+// measured each time the game is printed, so the layout follows the terminal when it's resized
+func WithTerminalWidth(out *os.File) NewSessionOption {
+	return func(s *Session) {
+		s.width = func() int { return TerminalWidth(out) }
+	}
+}
+
+// WithTileRenderer sets how the tiles are drawn. The PlainRenderer is used if it isn't set.
+func WithTileRenderer(renderer TileRenderer) NewSessionOption {
+	return func(s *Session) {
+		s.renderer = renderer
+	}
+}
+
+// NewSession creates a session that reads from r and writes to w. The reader is buffered once
+// for the whole session, so answers that arrive together, for example from a pipe, aren't lost
+// between prompts.
+func NewSession(r io.Reader, w io.Writer, opts ...NewSessionOption) *Session {
+	s := &Session{
+		reader:   bufio.NewReader(r),
+		writer:   w,
+		renderer: PlainRenderer{},
+		layout:   LayoutColumns,
+		width:    func() int { return 0 },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// QuitError is returned by the prompts when the player types "quit", or when there's no more
+// input to read
+type QuitError struct{}
+
+func (e QuitError) Error() string {
+	return "The player quit the game"
+}
+
+// helpText is shown when the player types "help" at any prompt
+const helpText = `On your turn, draw all the tiles of one color from a factory or from the center of the
+table, and place them on one of your pattern lines, or on the floor. A pattern line only
+takes one color, and only if that color isn't on the line's row of the wall yet. Tiles that
+don't fit on the line fall to the floor, and cost you points at the end of the round.
+
+A move can be typed on one line: 'f' and the factory's number or 'c' for the center, the
+color, and the line's number or 'floor', for example 'f2 blue 3' or 'c red floor'.
+
+Type 'help' at any prompt to see this again, or 'quit' to leave the game.`
+
 type PlaceFactoryTilesResponse struct {
 	PatternLineNumber int
 }
 
-func PromptToPlaceFactoryTiles() (PlaceFactoryTilesResponse, error) {
+// PromptToPlaceFactoryTiles asks the current player where to place the tiles of the color they
+// drew, until they choose a line the tiles can go on
+func (s *Session) PromptToPlaceFactoryTiles(game *models.Game, color models.TileColor) (PlaceFactoryTilesResponse, error) {
 	response := PlaceFactoryTilesResponse{}
 
-	patternLineNumber, err := PromptForInt("Which line would you like to place the tiles on?")
+	board := game.CurrentPlayer().Board
+	patternLineNumber, err := s.promptForValidInt(fmt.Sprintf("Which line would you like to place the tiles on? (enter %d for the floor)", models.FloorLine),
+		func(answer int) error {
+			return board.ValidatePlacement(answer, color)
+		})
 	if err != nil {
 		return response, err
 	}
@@ -32,18 +126,36 @@ type DrawFactoryTilesResponse struct {
 	TileColor      models.TileColor
 }
 
-func PromptToDrawFactoryTiles() (DrawFactoryTilesResponse, error) {
+// PromptToDrawFactoryTiles asks the current player which tiles to draw. Each answer is checked
+// against the game, and the player is asked again until they choose tiles that are there.
+func (s *Session) PromptToDrawFactoryTiles(game *models.Game) (DrawFactoryTilesResponse, error) {
 	response := DrawFactoryTilesResponse{}
 
-	drawSourceTypeStr, err := PromptForString("Would you like to draw from a factory or from the center of the table (type 'factory' or 'center')?")
+	_, err := s.PromptUntilValid("Would you like to draw from a factory or from the center of the table (type 'factory' or 'center')?", func(answer string) error {
+		switch source := models.DrawSourceType(strings.ToLower(answer)); source {
+		case models.DrawSourceFactory:
+			if len(factoriesWithTiles(game)) == 0 {
+				return models.InvalidActionError{Message: "The factories are empty, please draw from the center of the table"}
+			}
+			response.DrawSourceType = source
+		case models.DrawSourceCenter:
+			if err := validateDrawSource(game, models.Move{DrawSourceType: source}); err != nil {
+				return err
+			}
+			response.DrawSourceType = source
+		default:
+			return models.InvalidActionError{Message: fmt.Sprintf("'%s' is not a draw source, please type '%s' or '%s'", answer, models.DrawSourceFactory, models.DrawSourceCenter)}
+		}
+		return nil
+	})
 	if err != nil {
 		return response, err
 	}
-	//TODO: Validate this
-	response.DrawSourceType = models.DrawSourceType(drawSourceTypeStr)
 
 	if response.DrawSourceType == models.DrawSourceFactory {
-		factoryNumber, err := PromptForInt("Which factory would you like to draw from?")
+		factoryNumber, err := s.promptForValidInt("Which factory would you like to draw from?", func(answer int) error {
+			return validateDrawSource(game, models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: answer})
+		})
 		if err != nil {
 			return response, err
 		}
@@ -51,40 +163,98 @@ func PromptToDrawFactoryTiles() (DrawFactoryTilesResponse, error) {
 		response.FactoryNumber = factoryNumber
 	}
 
-	tileColor, err := PromptForString("Which color would you like to draw?")
+	tileColor, err := s.PromptUntilValid("Which color would you like to draw?", func(answer string) error {
+		return validateDrawColor(game, models.Move{
+			DrawSourceType: response.DrawSourceType,
+			FactoryNumber:  response.FactoryNumber,
+			TileColor:      models.TileColor(strings.ToLower(answer)),
+		})
+	})
 	if err != nil {
 		return response, err
 	}
-	response.TileColor = models.TileColor(tileColor)
+	response.TileColor = models.TileColor(strings.ToLower(tileColor))
 
 	return response, nil
 }
 
+// validateDrawSource checks that the move's draw source has tiles to draw
+func validateDrawSource(game *models.Game, move models.Move) error {
+	if move.DrawSourceType == models.DrawSourceCenter {
+		if len(colorsIn(game, game.CenterOfTheTable)) == 0 {
+			return models.InvalidActionError{Message: "There are no tiles in the center of the table, please draw from a factory"}
+		}
+		return nil
+	}
+	if factory, ok := game.Factories[move.FactoryNumber]; !ok || !factory.HasTiles() {
+		return models.InvalidActionError{Message: fmt.Sprintf("There are no tiles on factory #%d, please choose one of %v", move.FactoryNumber, factoriesWithTiles(game))}
+	}
+	return nil
+}
+
+// validateDrawColor checks that the move's draw source has tiles of the move's color. The draw
+// source must have been validated already.
+func validateDrawColor(game *models.Game, move models.Move) error {
+	source := game.CenterOfTheTable
+	if move.DrawSourceType == models.DrawSourceFactory {
+		source = game.Factories[move.FactoryNumber].TileCollection
+	}
+
+	colors := colorsIn(game, source)
+	for _, color := range colors {
+		if color == string(move.TileColor) {
+			return nil
+		}
+	}
+	return models.InvalidActionError{Message: fmt.Sprintf("There are no %s tiles to draw from %s, please choose one of %v", string(move.TileColor), drawSourceDisplay(move), colors)}
+}
+
+// factoriesWithTiles returns the numbers of the factories that still have tiles, in order
+func factoriesWithTiles(game *models.Game) []int {
+	numbers := make([]int, 0, len(game.Factories))
+	for i := 0; i < len(game.Factories); i++ {
+		if factory, ok := game.Factories[i]; ok && factory.HasTiles() {
+			numbers = append(numbers, i)
+		}
+	}
+	return numbers
+}
+
+// colorsIn returns the colors that can be drawn from the tiles, which leaves out the first
+// player tile
+func colorsIn(game *models.Game, tiles *models.TileCollection) []string {
+	colors := make([]string, 0, len(game.Config.TileColors))
+	for _, color := range game.Config.TileColors {
+		if tiles.HasTilesOfColor(color) {
+			colors = append(colors, string(color))
+		}
+	}
+	return colors
+}
+
 type NewPlayersResponse struct {
 	Players map[int]models.Player
 }
 
 //TODO: Separate prompting from validation and object creation
-func PromptForNewPlayers(config models.GameConfig) (NewPlayersResponse, error) {
+func (s *Session) PromptForNewPlayers(config models.GameConfig) (NewPlayersResponse, error) {
 	response := NewPlayersResponse{
 		Players: make(map[int]models.Player),
 	}
 
-	var numPlayers int
-	var err error
-	for numPlayers == 0 {
-		numPlayers, err = PromptForInt(fmt.Sprintf("How many players are playing the game? (min: %d; max: %d)", config.MinNumberOfPlayers, config.MaxNumberOfPlayers))
-		if err != nil {
-			return response, err
-		}
-
-		if numPlayers < config.MinNumberOfPlayers || numPlayers > config.MaxNumberOfPlayers {
-			fmt.Println("Invalid number of players")
-		}
+	numPlayers, err := s.promptForValidInt(fmt.Sprintf("How many players are playing the game? (min: %d; max: %d)", config.MinNumberOfPlayers, config.MaxNumberOfPlayers),
+		config.ValidateNumberOfPlayers)
+	if err != nil {
+		return response, err
 	}
 
 	for i := 0; i < numPlayers; i++ {
-		playerName, err := PromptForString(fmt.Sprintf("What is player #%d's name?", i))
+		playerName, err := s.PromptUntilValid(fmt.Sprintf("What is player #%d's name?", i), func(answer string) error {
+			if answer == "" {
+				return models.InvalidActionError{Message: "The name can't be empty"}
+			}
+			return nil
+		})
 		if err != nil {
 			return response, err
 		}
@@ -101,102 +271,214 @@ func PromptForNewPlayers(config models.GameConfig) (NewPlayersResponse, error) {
 	return response, nil
 }
 
-func PromptForString(prompt string) (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Println(prompt)
-	answer, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("failed to parse the response for '%s': %w", prompt, err)
-	}
-
-	answer = strings.Trim(answer, "\n")
+// PromptForString asks the question and returns the answer, without the surrounding spaces.
+// If the player types "help", the help is shown and the question is asked again. If they type
+// "quit", or there's nothing left to read, a QuitError is returned.
+func (s *Session) PromptForString(prompt string) (string, error) {
+	for {
+		fmt.Fprintln(s.writer, prompt)
+		answer, err := s.reader.ReadString('\n')
+		if err == io.EOF && answer == "" {
+			return "", QuitError{}
+		}
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to parse the response for '%s': %w", prompt, err)
+		}
 
-	return answer, nil
-}
+		answer = strings.TrimSpace(answer)
+		switch strings.ToLower(answer) {
+		case "quit":
+			return "", QuitError{}
+		case "help":
+			fmt.Fprintln(s.writer, helpText)
+			if legend := LegendFor(s.renderer); legend != "" {
+				fmt.Fprintln(s.writer)
+				fmt.Fprintln(s.writer, legend)
+			}
+			fmt.Fprintln(s.writer)
+			continue
+		}
 
-func PromptForInt(prompt string) (int, error) {
-	answerStr, err := PromptForString(prompt)
-	if err != nil {
-		return 0, err
+		return answer, nil
 	}
+}
 
-	answer, err := strconv.Atoi(answerStr)
-	if err != nil {
-		return 0, fmt.Errorf("failed to convert the answer to an integer: %w", err)
+// PromptUntilValid asks the question until validate accepts the answer. Each time it doesn't,
+// the player is told what was wrong.
+func (s *Session) PromptUntilValid(prompt string, validate func(answer string) error) (string, error) {
+	for {
+		answer, err := s.PromptForString(prompt)
+		if err != nil {
+			return "", err
+		}
+		if err := validate(answer); err != nil {
+			fmt.Fprintln(s.writer, err)
+			continue
+		}
+		return answer, nil
 	}
-
-	return answer, nil
 }
 
-func DisplayGameState(game *models.Game) {
-	// Print out the players and their boards
-	for i := 0; i < len(game.Players); i++ {
-		fmt.Println()
-		fmt.Printf("PLAYER #%d: %s\n", i, game.Players[i])
-
-		fmt.Println("Game Board:")
+// PromptForInt asks the question until the answer is a number
+func (s *Session) PromptForInt(prompt string) (int, error) {
+	return s.promptForValidInt(prompt, func(int) error { return nil })
+}
 
-		// Print the pattern lines
-		printPatternLines(game.Players[i].Board)
+func (s *Session) promptForValidInt(prompt string, validate func(answer int) error) (int, error) {
+	var answer int
+	_, err := s.PromptUntilValid(prompt, func(answerStr string) error {
+		var err error
+		if answer, err = strconv.Atoi(answerStr); err != nil {
+			return models.InvalidActionError{Message: fmt.Sprintf("'%s' is not a number", answerStr)}
+		}
+		return validate(answer)
+	})
+	return answer, err
+}
 
-		// Print the player's wall
-		printWall(game.Players[i].Board.Wall)
+// DisplayGameState prints the players' boards in the session's layout, and then the factories
+// and the center of the table. The boards and the factories are put side by side as far as
+// the width allows.
+func (s *Session) DisplayGameState(game *models.Game) {
+	width := s.width()
 
-		// Print the floor
-		printFloor(game.Players[i].Board.Floor)
+	if s.layout == LayoutSummary {
+		fmt.Fprintln(s.writer)
+		for i := 0; i < len(game.Players); i++ {
+			fmt.Fprintf(s.writer, "PLAYER #%d: %s\n", i, summaryLine(s.renderer, game.Players[i]))
+		}
+	} else {
+		boards := make([][]string, len(game.Players))
+		for i := range boards {
+			boards[i] = s.fullBoard(i, game.Players[i])
+		}
+		// If the whole boards don't fit, even one at a time, the compact ones are drawn instead
+		if s.layout == LayoutCompact || (width > 0 && blockWidth(boards[0]) > width) {
+			for i := range boards {
+				boards[i] = append([]string{fmt.Sprintf("PLAYER #%d: %s", i, game.Players[i])}, CompactBoard(s.renderer, game.Players[i].Board)...)
+			}
+		}
+		// Print out the players and their boards
+		fmt.Fprintln(s.writer)
+		for _, line := range ArrangeInColumns(boards, width, boardGap) {
+			fmt.Fprintln(s.writer, line)
+		}
 	}
-	fmt.Println()
+	fmt.Fprintln(s.writer)
 
 	// Print out the factories and their tiles
-	fmt.Println("FACTORIES:")
-	for i := 0; i < len(game.Factories); i++ {
-		fmt.Printf("Factory #%d: %s\n", i, game.Factories[i])
+	fmt.Fprintln(s.writer, "FACTORIES:")
+	factories := make([][]string, len(game.Factories))
+	for i := range factories {
+		factories[i] = []string{fmt.Sprintf("Factory #%d: %s", i, renderTiles(s.renderer, game.Factories[i].Tiles))}
+	}
+	for _, line := range ArrangeInColumns(factories, width, boardGap) {
+		// The factories are one line each, so the empty lines between their rows are left out
+		if line != "" {
+			fmt.Fprintln(s.writer, line)
+		}
 	}
 	// Print the tiles in center of the table
-	fmt.Printf("Center of the Table: %s\n", game.CenterOfTheTable.Tiles)
-	fmt.Println()
+	fmt.Fprintf(s.writer, "Center of the Table: %s\n", renderTiles(s.renderer, game.CenterOfTheTable.Tiles))
+	fmt.Fprintln(s.writer)
+
+	// The first time the game is shown, the symbols are explained. After that, they're in the help.
+	if legend := LegendFor(s.renderer); legend != "" && !s.legendShown {
+		fmt.Fprintln(s.writer, legend)
+		fmt.Fprintln(s.writer)
+		s.legendShown = true
+	}
 }
 
-func printPatternLines(board *models.Board) {
-	fmt.Println("Pattern Lines:")
-	//fmt.Printf("%v\n", board.PatternLines)
+// boardGap is the number of columns between boards, and between factories, side by side
+const boardGap = 4
 
+// fullBoard draws a player's whole board: the pattern lines, the wall and the floor, each under
+// a heading
+func (s *Session) fullBoard(playerKey int, player models.Player) []string {
+	lines := []string{
+		fmt.Sprintf("PLAYER #%d: %s", playerKey, player),
+		"Game Board:",
+	}
+
+	// The pattern lines
+	lines = append(lines, "Pattern Lines:")
+	board := player.Board
 	for line := 0; line < models.NumPatternLines; line++ {
 		lineString := fmt.Sprintf("%d", line)
 		for tile := 0; tile <= line; tile++ {
 			if tile >= len(board.PatternLines[line]) {
-				lineString = fmt.Sprintf("%s {%6s}", lineString, "empty")
+				lineString = fmt.Sprintf("%s %s", lineString, s.renderer.EmptySpace())
 			} else {
-				lineString = fmt.Sprintf("%s %s", lineString, board.PatternLines[line][tile])
+				lineString = fmt.Sprintf("%s %s", lineString, s.renderer.Tile(board.PatternLines[line][tile].Color))
 			}
 		}
-		fmt.Println(lineString)
+		lines = append(lines, lineString)
 	}
-}
 
-func printFloor(floor []models.FloorSpace) {
-	lineString := "Floor:"
+	// The player's wall
+	lines = append(lines, "Wall:")
+	for i := 0; i < len(board.Wall); i++ {
+		var row string
+		for j := 0; j < len(board.Wall[i]); j++ {
+			row = fmt.Sprintf("%s %s", row, s.renderer.WallSpace(board.Wall[i][j]))
+		}
+		lines = append(lines, row)
+	}
 
+	// The floor
+	floorString := "Floor:"
 	for tile := 0; tile < models.NumFloorSpaces; tile++ {
-		if tile >= len(floor) {
-			lineString = fmt.Sprintf("%s {%6s}(%d)", lineString, "empty", models.FloorScoreModifiers[tile])
+		if tile >= len(board.Floor) {
+			floorString = fmt.Sprintf("%s %s(%d)", floorString, s.renderer.EmptySpace(), models.FloorScoreModifiers[tile])
 		} else {
-			lineString = fmt.Sprintf("%s %s", lineString, floor[tile])
+			floorString = fmt.Sprintf("%s %s(%d)", floorString, s.renderer.Tile(board.Floor[tile].Color), board.Floor[tile].ScoreModifier)
 		}
 	}
-
-	fmt.Println(lineString)
+	return append(lines, floorString)
 }
 
-func printWall(wall [][]models.WallSpace) {
-	fmt.Println("Wall:")
-
-	for i := 0; i < len(wall); i++ {
-		var row string
-		for j := 0; j < len(wall[i]); j++ {
-			row = fmt.Sprintf("%s %s", row, wall[i][j])
+// DisplayEvent prints a one-line description of a game event. The scoring at the end of a
+// round is printed all together, as a report for each player, when the round is over. It can
+// be subscribed to a game with models.ObserverFunc(session.DisplayEvent).
+func (s *Session) DisplayEvent(event models.Event) {
+	switch e := event.(type) {
+	case models.GameStarted:
+		fmt.Fprintf(s.writer, "The game is starting with %d players. %s goes first.\n", len(e.Players), e.Players[e.FirstPlayer])
+	case models.FactoriesFilled:
+		fmt.Fprintf(s.writer, "ROUND %d: the factories have been filled\n", e.Round)
+	case models.TilesDrawn:
+		fmt.Fprintf(s.writer, "%s drew %d %s tile(s) from %s\n", e.PlayerName, len(e.Tiles), string(e.Move.TileColor), drawSourceDisplay(e.Move))
+	case models.LeftoversMovedToCenter:
+		fmt.Fprintf(s.writer, "The leftover tiles from factory #%d were moved to the center of the table: %s\n", e.FactoryNumber, renderTiles(s.renderer, e.Tiles))
+	case models.TilesPlaced:
+		if e.PatternLineNumber == models.FloorLine {
+			fmt.Fprintf(s.writer, "%s placed the tiles on the floor\n", e.PlayerName)
+		} else {
+			fmt.Fprintf(s.writer, "%s placed the tiles on pattern line #%d\n", e.PlayerName, e.PatternLineNumber)
+		}
+	case models.FloorOverflow:
+		fmt.Fprintf(s.writer, "%s's floor is full, %d tile(s) were discarded\n", e.PlayerName, len(e.Tiles))
+	case models.WallTiled, models.FloorScored:
+		// The scoring is reported for the whole round once it's over
+		s.collectRoundScoring(e)
+	case models.RoundEnded:
+		s.displayRoundReport(e)
+	case models.GameEnded:
+		fmt.Fprintln(s.writer, "GAME OVER")
+		for _, winner := range e.Winners {
+			name, ok := e.PlayerNames[winner]
+			if !ok {
+				name = fmt.Sprintf("Player #%d", winner)
+			}
+			fmt.Fprintf(s.writer, "%s wins with %d points!\n", name, e.Scores[winner])
 		}
-		fmt.Println(row)
 	}
 }
+
+func drawSourceDisplay(move models.Move) string {
+	if move.DrawSourceType == models.DrawSourceCenter {
+		return "the center of the table"
+	}
+	return fmt.Sprintf("factory #%d", move.FactoryNumber)
+}
@@ -0,0 +1,202 @@
+package interactions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+	"github.com/aaron-zeisler/azul/internal/testutils"
+)
+
+func newTestGame() *models.Game {
+	players := map[int]models.Player{
+		0: models.NewPlayer("alice", models.FirstPlayer()),
+		1: models.NewPlayer("bob"),
+	}
+	return models.NewGame(models.WithPlayers(players), models.WithSeed(1))
+}
+
+// setFactories replaces the tiles on the game's factories. Factories that aren't specified
+// are emptied.
+func setFactories(game *models.Game, factories map[int][]models.Tile) {
+	for i, factory := range game.Factories {
+		factory.Tiles = append([]models.Tile{}, factories[i]...)
+	}
+}
+
+func TestSession_PromptForInt(t *testing.T) {
+	testCases := map[string]struct {
+		input          string
+		expectedAnswer int
+		expectedErr    error
+		expectedOutput string
+	}{
+		"A number":                    {input: "3\n", expectedAnswer: 3},
+		"Spaces around the number":    {input: "  3 \r\n", expectedAnswer: 3},
+		"The last line has no ending": {input: "3", expectedAnswer: 3},
+		"Not a number, then a number": {input: "three\n3\n", expectedAnswer: 3, expectedOutput: "'three' is not a number"},
+		"Help, then a number":         {input: "help\n3\n", expectedAnswer: 3, expectedOutput: "Type 'help' at any prompt"},
+		"Quit":                        {input: "QUIT\n3\n", expectedErr: QuitError{}},
+		"Nothing to read":             {input: "", expectedErr: QuitError{}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			var output bytes.Buffer
+			s := NewSession(strings.NewReader(tc.input), &output)
+
+			answer, err := s.PromptForInt("How many?")
+			assert.So(err, testutils.ShouldEqualError, tc.expectedErr)
+			assert.So(answer, should.Equal, tc.expectedAnswer)
+			assert.So(output.String(), should.StartWith, "How many?\n")
+			assert.So(output.String(), should.ContainSubstring, tc.expectedOutput)
+		})
+	}
+}
+
+func TestSession_PromptToDrawFactoryTiles(t *testing.T) {
+	testCases := map[string]struct {
+		factories        map[int][]models.Tile
+		input            string
+		expectedResponse DrawFactoryTilesResponse
+		expectedErr      error
+		expectedOutput   []string
+	}{
+		"Draw from a factory": {
+			factories:        map[int][]models.Tile{1: {{Color: models.Blue}, {Color: models.Red}}},
+			input:            "factory\n1\nred\n",
+			expectedResponse: DrawFactoryTilesResponse{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Red},
+		},
+		"Wrong answers are explained, and asked again": {
+			factories:        map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:            "table\ncenter\nFactory\n7\n0\n1\nred\nBLUE\n",
+			expectedResponse: DrawFactoryTilesResponse{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Blue},
+			expectedOutput: []string{
+				"'table' is not a draw source",
+				"There are no tiles in the center of the table",
+				"There are no tiles on factory #7, please choose one of [1]",
+				"There are no tiles on factory #0, please choose one of [1]",
+				"There are no red tiles to draw from factory #1, please choose one of [blue]",
+			},
+		},
+		"A factory that isn't a number": {
+			factories:        map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:            "factory\none\n#1\n-1\n1\nblue\n",
+			expectedResponse: DrawFactoryTilesResponse{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Blue},
+			expectedOutput: []string{
+				"'one' is not a number",
+				"'#1' is not a number",
+				"There are no tiles on factory #-1, please choose one of [1]",
+			},
+		},
+		"Help at each question": {
+			factories:        map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:            "help\nfactory\nhelp\n1\nhelp\nblue\n",
+			expectedResponse: DrawFactoryTilesResponse{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Blue},
+			expectedOutput:   []string{"Type 'help' at any prompt"},
+		},
+		"Quit instead of choosing a source": {
+			factories:   map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:       "quit\n",
+			expectedErr: QuitError{},
+		},
+		"Quit instead of choosing a factory": {
+			factories:   map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:       "factory\n7\nquit\n",
+			expectedErr: QuitError{},
+		},
+		"Quit instead of choosing a color": {
+			factories:   map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:       "factory\n1\nQuit\n",
+			expectedErr: QuitError{},
+		},
+		"Nothing left to read": {
+			factories:   map[int][]models.Tile{1: {{Color: models.Blue}}},
+			input:       "factory\n",
+			expectedErr: QuitError{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+			setFactories(game, tc.factories)
+			var output bytes.Buffer
+			s := NewSession(strings.NewReader(tc.input), &output)
+
+			response, err := s.PromptToDrawFactoryTiles(game)
+			assert.So(err, testutils.ShouldEqualError, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.So(response, should.Resemble, tc.expectedResponse)
+			}
+			for _, expected := range tc.expectedOutput {
+				assert.So(output.String(), should.ContainSubstring, expected)
+			}
+		})
+	}
+}
+
+func TestSession_PromptToPlaceFactoryTiles(t *testing.T) {
+	testCases := map[string]struct {
+		input          string
+		expectedLine   int
+		expectedErr    error
+		expectedOutput []string
+	}{
+		"Wrong answers are explained, and asked again": {
+			input:          "9\n2\n-1\n",
+			expectedLine:   models.FloorLine,
+			expectedOutput: []string{"There is no pattern line #9", "red tiles on it"},
+		},
+		"A line that isn't a number": {
+			input:          "floor\nthree\n3\n",
+			expectedLine:   3,
+			expectedOutput: []string{"'floor' is not a number", "'three' is not a number"},
+		},
+		"Help, then a line": {
+			input:          "help\n3\n",
+			expectedLine:   3,
+			expectedOutput: []string{"Type 'help' at any prompt"},
+		},
+		"Quit":                 {input: "9\nquit\n", expectedErr: QuitError{}},
+		"Nothing left to read": {input: "2\n", expectedErr: QuitError{}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+			game.CurrentPlayer().Board.PatternLines[2] = append(game.CurrentPlayer().Board.PatternLines[2], models.Tile{Color: models.Red})
+			var output bytes.Buffer
+			s := NewSession(strings.NewReader(tc.input), &output)
+
+			response, err := s.PromptToPlaceFactoryTiles(game, models.Blue)
+			assert.So(err, testutils.ShouldEqualError, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.So(response.PatternLineNumber, should.Equal, tc.expectedLine)
+			}
+			for _, expected := range tc.expectedOutput {
+				assert.So(output.String(), should.ContainSubstring, expected)
+			}
+		})
+	}
+}
+
+func TestSession_DisplayEvent_GameEnded(t *testing.T) {
+	assert := assertions.New(t)
+	var output bytes.Buffer
+	s := NewSession(strings.NewReader(""), &output)
+
+	s.DisplayEvent(models.GameEnded{
+		PlayerNames: map[int]string{0: "alice", 1: "bob", 2: "carol"},
+		Scores:      map[int]int{0: 52, 1: 61, 2: 61},
+		Winners:     []int{1, 2},
+	})
+	assert.So(output.String(), should.Equal, "GAME OVER\nbob wins with 61 points!\ncarol wins with 61 points!\n")
+}
@@ -0,0 +1,242 @@
+package interactions
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Layout is how DisplayGameState arranges the players' boards
+type Layout int
+
+const (
+	// LayoutColumns draws the whole boards side by side, as many as fit across the terminal. If
+	// not even one fits, the compact boards are drawn instead.
+	LayoutColumns Layout = iota
+	// LayoutCompact draws each board in a few short lines, with the pattern lines next to the
+	// wall, side by side like LayoutColumns
+	LayoutCompact
+	// LayoutSummary draws one line for each player
+	LayoutSummary
+)
+
+// LayoutVariable is the environment variable that chooses the DefaultLayout: "columns",
+// "compact" or "summary"
+const LayoutVariable = "AZUL_LAYOUT"
+
+// DefaultLayout returns the layout chosen with the AZUL_LAYOUT environment variable, or
+// LayoutColumns
+func DefaultLayout() Layout {
+	switch os.Getenv(LayoutVariable) {
+	case "compact":
+		return LayoutCompact
+	case "summary":
+		return LayoutSummary
+	}
+	return LayoutColumns
+}
+
+// TerminalWidth is the number of columns on the terminal that out writes to, or 0 if out isn't
+// a terminal, or its size can't be found out
+func TerminalWidth(out *os.File) int {
+	info, err := out.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return 0
+	}
+	cmd := exec.Command("stty", "size")
+	cmd.Stdin = out
+	size, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+	// The size is the number of rows and then the number of columns
+	fields := strings.Fields(string(size))
+	if len(fields) != 2 {
+		return 0
+	}
+	columns, err := strconv.Atoi(fields[1])
+	if err != nil || columns < 0 {
+		return 0
+	}
+	return columns
+}
+
+// CompactBoard draws a player's pattern lines next to their wall, one line for each, and their
+// floor below, with what it costs them so far. The pattern lines are numbered, and lined up on
+// the right against the wall.
+func CompactBoard(r TileRenderer, board *models.Board) []string {
+	lines := make([]string, 0, models.NumPatternLines+1)
+	emptyWidth := VisibleWidth(r.EmptySpace())
+	for line := 0; line < models.NumPatternLines; line++ {
+		cells := make([]string, 0, line+1)
+		for i := 0; i <= line; i++ {
+			if i < len(board.PatternLines[line]) {
+				cells = append(cells, r.Tile(board.PatternLines[line][i].Color))
+			} else {
+				cells = append(cells, r.EmptySpace())
+			}
+		}
+		padding := strings.Repeat(" ", (models.NumPatternLines-1-line)*(emptyWidth+1))
+
+		var wall []string
+		if line < len(board.Wall) {
+			for _, space := range board.Wall[line] {
+				wall = append(wall, r.WallSpace(space))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%d %s%s | %s", line, padding, strings.Join(cells, " "), strings.Join(wall, " ")))
+	}
+
+	floor := make([]string, models.NumFloorSpaces)
+	for i := range floor {
+		if i < len(board.Floor) {
+			floor[i] = r.Tile(board.Floor[i].Color)
+		} else {
+			floor[i] = r.EmptySpace()
+		}
+	}
+	return append(lines, fmt.Sprintf("Floor %s (%d)", strings.Join(floor, " "), floorPenalty(board)))
+}
+
+// summaryLine describes a player's board on one line: their score, the tiles on each pattern
+// line, how many tiles are on their wall, and what their floor costs them so far
+func summaryLine(r TileRenderer, player models.Player) string {
+	board := player.Board
+	patternLines := make([]string, models.NumPatternLines)
+	for line := range patternLines {
+		var cells string
+		for i := 0; i <= line; i++ {
+			if i < len(board.PatternLines[line]) {
+				cells += r.Tile(board.PatternLines[line][i].Color)
+			} else {
+				cells += r.EmptySpace()
+			}
+		}
+		patternLines[line] = cells
+	}
+
+	wallTiles := 0
+	for _, row := range board.Wall {
+		for _, space := range row {
+			if space.HasTile {
+				wallTiles++
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s | lines %s | wall %d tile(s) | floor %d", player, strings.Join(patternLines, " "), wallTiles, floorPenalty(board))
+}
+
+// floorPenalty is what the tiles on the floor cost, which is negative
+func floorPenalty(board *models.Board) int {
+	penalty := 0
+	for _, space := range board.Floor {
+		penalty += space.ScoreModifier
+	}
+	return penalty
+}
+
+// VisibleWidth is the number of columns the text takes up on a terminal, which leaves out the
+// ANSI escape codes in it
+func VisibleWidth(s string) int {
+	const (
+		text = iota
+		escape
+		controlSequence
+	)
+	width, state := 0, text
+	for _, r := range s {
+		switch state {
+		case text:
+			if r == '\x1b' {
+				state = escape
+			} else {
+				width++
+			}
+		case escape:
+			state = text
+			if r == '[' {
+				state = controlSequence
+			}
+		case controlSequence:
+			// A control sequence ends with a letter or one of a few symbols
+			if r >= '@' && r <= '~' {
+				state = text
+			}
+		}
+	}
+	return width
+}
+
+// padRight pads the text with spaces until it takes up the width
+func padRight(s string, width int) string {
+	if pad := width - VisibleWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// ArrangeInColumns places blocks of lines side by side, gap columns apart. When the next block
+// wouldn't fit in the width, it starts a new row of blocks below the others, after an empty
+// line. A block that's wider than the width gets a row of its own.
+func ArrangeInColumns(blocks [][]string, width int, gap int) []string {
+	var lines []string
+	for start := 0; start < len(blocks); {
+		// Fill the row with as many blocks as fit
+		end, rowWidth := start, 0
+		widths := make([]int, 0, len(blocks)-start)
+		for end < len(blocks) {
+			blockWidth := blockWidth(blocks[end])
+			if end > start && rowWidth+gap+blockWidth > width {
+				break
+			}
+			if end > start {
+				rowWidth += gap
+			}
+			rowWidth += blockWidth
+			widths = append(widths, blockWidth)
+			end++
+		}
+
+		if start > 0 {
+			lines = append(lines, "")
+		}
+		height := 0
+		for _, block := range blocks[start:end] {
+			if len(block) > height {
+				height = len(block)
+			}
+		}
+		for i := 0; i < height; i++ {
+			var line string
+			for j, block := range blocks[start:end] {
+				var blockLine string
+				if i < len(block) {
+					blockLine = block[i]
+				}
+				if j < end-start-1 {
+					blockLine = padRight(blockLine, widths[j]+gap)
+				}
+				line += blockLine
+			}
+			lines = append(lines, strings.TrimRight(line, " "))
+		}
+		start = end
+	}
+	return lines
+}
+
+// blockWidth is the width of the widest line in the block
+func blockWidth(lines []string) int {
+	width := 0
+	for _, line := range lines {
+		if w := VisibleWidth(line); w > width {
+			width = w
+		}
+	}
+	return width
+}
@@ -0,0 +1,121 @@
+package interactions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	assert := assertions.New(t)
+	assert.So(VisibleWidth("{  blue}"), should.Equal, 8)
+	assert.So(VisibleWidth(ColorRenderer{}.Tile(models.Blue)), should.Equal, 2)
+	assert.So(VisibleWidth(ColorRenderer{}.WallSpace(models.WallSpace{Tile: models.Tile{Color: models.Red}})), should.Equal, 2)
+	assert.So(VisibleWidth("▶ F2 ◀"), should.Equal, 6)
+}
+
+func TestArrangeInColumns(t *testing.T) {
+	blocks := [][]string{
+		{"aaa", "a"},
+		{"bb", "bb", "bb"},
+		{"\x1b[2mc\x1b[0m"},
+	}
+	testCases := map[string]struct {
+		width         int
+		expectedLines []string
+	}{
+		"Everything fits": {
+			width:         20,
+			expectedLines: []string{"aaa  bb  \x1b[2mc\x1b[0m", "a    bb", "     bb"},
+		},
+		"Two rows": {
+			width:         8,
+			expectedLines: []string{"aaa  bb", "a    bb", "     bb", "", "\x1b[2mc\x1b[0m"},
+		},
+		"Too narrow for any two blocks": {
+			width:         2,
+			expectedLines: []string{"aaa", "a", "", "bb", "bb", "bb", "", "\x1b[2mc\x1b[0m"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			assert.So(ArrangeInColumns(blocks, tc.width, 2), should.Resemble, tc.expectedLines)
+		})
+	}
+}
+
+func TestCompactBoard(t *testing.T) {
+	assert := assertions.New(t)
+	board := models.NewPlayer("alice").Board
+	_, err := board.PlaceTiles(1, []models.Tile{{Color: models.Red}})
+	assert.So(err, should.BeNil)
+	board.AddToFloor([]models.Tile{{Color: models.FirstPlayerTile}, {Color: models.Blue}})
+	board.Wall[0][0].HasTile = true
+
+	assert.So(CompactBoard(SymbolRenderer{}, board), should.Resemble, []string{
+		"0         . | B o r k w",
+		"1       R . | w b o r k",
+		"2     . . . | k w b o r",
+		"3   . . . . | r k w b o",
+		"4 . . . . . | o r k w b",
+		"Floor ★ B . . . . . (-2)",
+	})
+}
+
+func TestSession_DisplayGameState_Layouts(t *testing.T) {
+	testCases := map[string]struct {
+		opts             []NewSessionOption
+		expectedLines    []string
+		notExpectedLines []string
+	}{
+		"No width, one board under the other": {
+			expectedLines:    []string{"\nPLAYER #0: alice - 0 Points - First Player\n", "\nPLAYER #1: bob - 0 Points\n", "\nFactory #0: [K B]\nFactory #1: []\n"},
+			notExpectedLines: []string{"  PLAYER #1"},
+		},
+		"Wide enough for the boards side by side": {
+			opts:          []NewSessionOption{WithWidth(120)},
+			expectedLines: []string{"\nPLAYER #0: alice - 0 Points - First Player          PLAYER #1: bob - 0 Points\n", "\nFactory #0: [K B]    Factory #1: []    Factory #2: []"},
+		},
+		"Too narrow for a whole board": {
+			opts:          []NewSessionOption{WithWidth(40)},
+			expectedLines: []string{"\nPLAYER #0: alice - 0 Points - First Player\n0         . | b o r k w\n", "\nFloor . . . . . . . (0)\n\nPLAYER #1"},
+		},
+		"Compact": {
+			opts:          []NewSessionOption{WithWidth(120), WithLayout(LayoutCompact)},
+			expectedLines: []string{"\nPLAYER #0: alice - 0 Points - First Player    PLAYER #1: bob - 0 Points\n0         . | b o r k w                       0         . | b o r k w\n"},
+		},
+		"Summary": {
+			opts: []NewSessionOption{WithLayout(LayoutSummary)},
+			expectedLines: []string{
+				"\nPLAYER #0: alice - 0 Points - First Player | lines . .. ... .... ..... | wall 0 tile(s) | floor 0\n" +
+					"PLAYER #1: bob - 0 Points | lines . .. ... .... ..... | wall 0 tile(s) | floor 0\n\nFACTORIES:",
+			},
+			notExpectedLines: []string{"Wall:", "|  b o r k w"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+			setFactories(game, map[int][]models.Tile{0: {{Color: models.Black}, {Color: models.Blue}}})
+			var output bytes.Buffer
+			s := NewSession(strings.NewReader(""), &output, append([]NewSessionOption{WithTileRenderer(SymbolRenderer{})}, tc.opts...)...)
+
+			s.DisplayGameState(game)
+			for _, line := range tc.expectedLines {
+				assert.So(output.String(), should.ContainSubstring, line)
+			}
+			for _, line := range tc.notExpectedLines {
+				assert.So(output.String(), should.NotContainSubstring, line)
+			}
+		})
+	}
+}
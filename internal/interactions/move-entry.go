@@ -0,0 +1,160 @@
+package interactions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// A whole move can be typed on one line: the draw source, the color, and where to place the
+// tiles, for example "f2 blue 3" to draw the blue tiles from factory #2 and place them on
+// pattern line #3, or "c red floor" to draw the red tiles from the center of the table and
+// place them on the floor.
+
+const moveEntryPrompt = "Enter your move, for example 'f2 blue 3' or 'c red floor' (or just press enter to be asked step by step):"
+
+// MoveEntryError is a mistake in a move typed on one line. Start and End are the positions
+// in the line of the word that's wrong, or the end of the line if a word is missing.
+type MoveEntryError struct {
+	Message string
+	Line    string
+	Start   int
+	End     int
+}
+
+func (e MoveEntryError) Error() string {
+	return e.Message
+}
+
+// Pointer returns the line, with the word that's wrong marked underneath it
+func (e MoveEntryError) Pointer() string {
+	width := e.End - e.Start
+	if width < 1 {
+		width = 1
+	}
+	return fmt.Sprintf("%s\n%s%s", e.Line, strings.Repeat(" ", e.Start), strings.Repeat("^", width))
+}
+
+// moveToken is a word of a move typed on one line, and where it is in the line
+type moveToken struct {
+	text       string
+	start, end int
+}
+
+func tokenizeMove(line string) []moveToken {
+	var tokens []moveToken
+	start := -1
+	for i, r := range line + " " {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			tokens = append(tokens, moveToken{text: strings.ToLower(line[start:i]), start: start, end: i})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+	return tokens
+}
+
+// ParseMoveEntry reads a move typed on one line, and checks it against the game. If anything
+// is wrong, a MoveEntryError points at the word that's wrong.
+func ParseMoveEntry(game *models.Game, line string) (models.Move, error) {
+	move := models.Move{}
+	tokens := tokenizeMove(line)
+	fail := func(token moveToken, message string) (models.Move, error) {
+		return models.Move{}, MoveEntryError{Message: message, Line: line, Start: token.start, End: token.end}
+	}
+	if len(tokens) < 3 {
+		end := moveToken{start: len(line), end: len(line)}
+		missing := []string{"the draw source", "the color", "the line to place the tiles on"}[len(tokens)]
+		return fail(end, fmt.Sprintf("%s is missing, a move is the draw source, the color, and the line, for example 'f2 blue 3'", missing))
+	}
+	if len(tokens) > 3 {
+		return fail(tokens[3], fmt.Sprintf("'%s' is one word too many, a move is the draw source, the color, and the line, for example 'f2 blue 3'", tokens[3].text))
+	}
+	source, color, destination := tokens[0], tokens[1], tokens[2]
+
+	switch {
+	case source.text == "c" || source.text == string(models.DrawSourceCenter):
+		move.DrawSourceType = models.DrawSourceCenter
+	case strings.HasPrefix(source.text, "f"):
+		factoryNumber, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(source.text, string(models.DrawSourceFactory)), "f"))
+		if err != nil {
+			return fail(source, fmt.Sprintf("'%s' is not a factory, please type 'f' and the factory's number, for example 'f2'", source.text))
+		}
+		move.DrawSourceType = models.DrawSourceFactory
+		move.FactoryNumber = factoryNumber
+	default:
+		return fail(source, fmt.Sprintf("'%s' is not a draw source, please type 'f' and a factory's number, or 'c' for the center of the table", source.text))
+	}
+	if err := validateDrawSource(game, move); err != nil {
+		return fail(source, err.Error())
+	}
+
+	move.TileColor = models.TileColor(color.text)
+	if err := validateDrawColor(game, move); err != nil {
+		return fail(color, err.Error())
+	}
+
+	switch {
+	case destination.text == "floor":
+		move.PatternLineNumber = models.FloorLine
+	default:
+		lineNumber, err := strconv.Atoi(strings.TrimPrefix(destination.text, "l"))
+		if err != nil {
+			return fail(destination, fmt.Sprintf("'%s' is not a pattern line, please type the line's number, or 'floor'", destination.text))
+		}
+		move.PatternLineNumber = lineNumber
+	}
+	if err := game.CurrentPlayer().Board.ValidatePlacement(move.PatternLineNumber, move.TileColor); err != nil {
+		return fail(destination, err.Error())
+	}
+
+	return move, nil
+}
+
+// PromptForMove asks the current player for their move on one line. If they just press enter,
+// they're asked for it step by step instead.
+func (s *Session) PromptForMove(game *models.Game) (models.Move, error) {
+	for {
+		line, err := s.PromptForString(moveEntryPrompt)
+		if err != nil {
+			return models.Move{}, err
+		}
+		if line == "" {
+			return s.promptForMoveStepByStep(game)
+		}
+
+		move, err := ParseMoveEntry(game, line)
+		if entryErr, ok := err.(MoveEntryError); ok {
+			fmt.Fprintln(s.writer, entryErr.Pointer())
+			fmt.Fprintln(s.writer, entryErr)
+			continue
+		}
+		return move, err
+	}
+}
+
+// promptForMoveStepByStep asks the current player which tiles to draw, and then where to
+// place them
+func (s *Session) promptForMoveStepByStep(game *models.Game) (models.Move, error) {
+	drawResponse, err := s.PromptToDrawFactoryTiles(game)
+	if err != nil {
+		return models.Move{}, err
+	}
+
+	placeResponse, err := s.PromptToPlaceFactoryTiles(game, drawResponse.TileColor)
+	if err != nil {
+		return models.Move{}, err
+	}
+
+	return models.Move{
+		DrawSourceType:    drawResponse.DrawSourceType,
+		FactoryNumber:     drawResponse.FactoryNumber,
+		TileColor:         drawResponse.TileColor,
+		PatternLineNumber: placeResponse.PatternLineNumber,
+	}, nil
+}
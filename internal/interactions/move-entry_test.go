@@ -0,0 +1,117 @@
+package interactions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func TestParseMoveEntry(t *testing.T) {
+	type expected struct {
+		move    models.Move
+		message string
+		start   int
+		end     int
+	}
+	testCases := map[string]struct {
+		line     string
+		expected expected
+	}{
+		"A factory and a pattern line": {
+			line:     "f1 blue 3",
+			expected: expected{move: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Blue, PatternLineNumber: 3}},
+		},
+		"The center and the floor": {
+			line:     "c red floor",
+			expected: expected{move: models.Move{DrawSourceType: models.DrawSourceCenter, TileColor: models.Red, PatternLineNumber: models.FloorLine}},
+		},
+		"Long words, capitals and extra spaces": {
+			line:     "  Factory1   BLUE  L0 ",
+			expected: expected{move: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Blue, PatternLineNumber: 0}},
+		},
+		"Error case: the color is missing": {
+			line:     "f1",
+			expected: expected{message: "the color is missing", start: 2, end: 2},
+		},
+		"Error case: one word too many": {
+			line:     "f1 blue 3 please",
+			expected: expected{message: "'please' is one word too many", start: 10, end: 16},
+		},
+		"Error case: not a draw source": {
+			line:     "x1 blue 3",
+			expected: expected{message: "'x1' is not a draw source", start: 0, end: 2},
+		},
+		"Error case: an empty factory": {
+			line:     "f0 blue 3",
+			expected: expected{message: "There are no tiles on factory #0", start: 0, end: 2},
+		},
+		"Error case: a color that isn't there": {
+			line:     "f1 purple 3",
+			expected: expected{message: "There are no purple tiles to draw from factory #1, please choose one of [blue]", start: 3, end: 9},
+		},
+		"Error case: the first player tile can't be drawn": {
+			line:     "c 1stplayer 3",
+			expected: expected{message: "There are no 1stplayer tiles to draw from the center of the table", start: 2, end: 11},
+		},
+		"Error case: not a pattern line": {
+			line:     "f1 blue top",
+			expected: expected{message: "'top' is not a pattern line", start: 8, end: 11},
+		},
+		"Error case: a pattern line that doesn't exist": {
+			line:     "f1 blue 7",
+			expected: expected{message: "There is no pattern line #7", start: 8, end: 9},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+			setFactories(game, map[int][]models.Tile{1: {{Color: models.Blue}}})
+			game.CenterOfTheTable.Tiles = []models.Tile{{Color: models.FirstPlayerTile}, {Color: models.Red}}
+
+			move, err := ParseMoveEntry(game, tc.line)
+			if tc.expected.message == "" {
+				assert.So(err, should.BeNil)
+				assert.So(move, should.Resemble, tc.expected.move)
+				return
+			}
+			entryErr, ok := err.(MoveEntryError)
+			assert.So(ok, should.BeTrue)
+			assert.So(entryErr.Message, should.ContainSubstring, tc.expected.message)
+			assert.So(entryErr.Start, should.Equal, tc.expected.start)
+			assert.So(entryErr.End, should.Equal, tc.expected.end)
+		})
+	}
+}
+
+func TestSession_PromptForMove(t *testing.T) {
+	testCases := map[string]struct {
+		input          string
+		expectedOutput string
+	}{
+		"On one line":                     {input: "f1 blue 3\n"},
+		"A mistake, and then on one line": {input: "f1 purple 3\nf1 blue 3\n", expectedOutput: "f1 purple 3\n   ^^^^^^\n"},
+		"Step by step":                    {input: "\nfactory\n1\nblue\n3\n", expectedOutput: "Which factory would you like to draw from?"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+			setFactories(game, map[int][]models.Tile{1: {{Color: models.Blue}}})
+			var output bytes.Buffer
+			s := NewSession(strings.NewReader(tc.input), &output)
+
+			move, err := s.PromptForMove(game)
+			assert.So(err, should.BeNil)
+			assert.So(move, should.Resemble, models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Blue, PatternLineNumber: 3})
+			assert.So(output.String(), should.ContainSubstring, tc.expectedOutput)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package interactions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// TileRenderer draws the tiles and spaces that the boards, factories and center are made of
+type TileRenderer interface {
+	// Tile draws a tile of the color
+	Tile(color models.TileColor) string
+	// WallSpace draws a space on the wall, which shows its color whether or not it has a tile
+	WallSpace(space models.WallSpace) string
+	// EmptySpace draws an empty space on a pattern line or the floor
+	EmptySpace() string
+}
+
+// PlainRenderer draws the tiles as their padded color names, for example "{  blue}", and the
+// wall spaces as "{  blue [x]}", where the x means the space has a tile
+type PlainRenderer struct{}
+
+func (r PlainRenderer) Tile(color models.TileColor) string {
+	return fmt.Sprint(models.Tile{Color: color})
+}
+
+func (r PlainRenderer) WallSpace(space models.WallSpace) string {
+	return space.String()
+}
+
+func (r PlainRenderer) EmptySpace() string {
+	return fmt.Sprintf("{%6s}", "empty")
+}
+
+// ColorRenderer draws the tiles as blocks of color with ANSI escape codes. An empty space on
+// the wall is a dim version of its color, so it's easy to see which color goes where.
+type ColorRenderer struct{}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+)
+
+// tileColorCodes are the colors of the tiles in the 256-color palette
+var tileColorCodes = map[models.TileColor]int{
+	models.Blue:   33,
+	models.Orange: 208,
+	models.Red:    160,
+	models.Black:  240,
+	models.White:  255,
+}
+
+func (r ColorRenderer) Tile(color models.TileColor) string {
+	if color == models.FirstPlayerTile {
+		// The first player tile is black on white, and marked with a 1
+		return "\x1b[30;47m1 " + ansiReset
+	}
+	code, ok := tileColorCodes[color]
+	if !ok {
+		return "??"
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm██%s", code, ansiReset)
+}
+
+func (r ColorRenderer) WallSpace(space models.WallSpace) string {
+	if space.HasTile {
+		return r.Tile(space.Color)
+	}
+	return fmt.Sprintf("%s\x1b[38;5;%dm░░%s", ansiDim, tileColorCodes[space.Color], ansiReset)
+}
+
+func (r ColorRenderer) EmptySpace() string {
+	return ansiDim + "··" + ansiReset
+}
+
+// SymbolRenderer draws each tile as a letter of its own, so the tiles can be told apart without
+// their colors, and read out by a screen reader: B for blue, O for orange, R for red, K for
+// black, W for white, and a star for the first player tile. An empty space on the wall shows
+// the lower-case letter of the color that goes there. Only plain text is written.
+type SymbolRenderer struct{}
+
+// tileSymbols are the letters the SymbolRenderer draws the tiles with
+var tileSymbols = map[models.TileColor]string{
+	models.Blue:            "B",
+	models.Orange:          "O",
+	models.Red:             "R",
+	models.Black:           "K",
+	models.White:           "W",
+	models.FirstPlayerTile: "★",
+}
+
+func (r SymbolRenderer) Tile(color models.TileColor) string {
+	if symbol, ok := tileSymbols[color]; ok {
+		return symbol
+	}
+	return "?"
+}
+
+func (r SymbolRenderer) WallSpace(space models.WallSpace) string {
+	if space.HasTile {
+		return r.Tile(space.Color)
+	}
+	return strings.ToLower(r.Tile(space.Color))
+}
+
+func (r SymbolRenderer) EmptySpace() string {
+	return "."
+}
+
+// Legend explains the symbols, on two lines that fit on a narrow terminal
+func (r SymbolRenderer) Legend() string {
+	return "Tiles: B blue, O orange, R red, K black, W white, ★ first player, . empty space\n" +
+		"A lower-case letter is an empty space on the wall for that color."
+}
+
+// LegendFor explains the renderer's tiles, if they need explaining like the SymbolRenderer's,
+// or returns an empty string
+func LegendFor(r TileRenderer) string {
+	if withLegend, ok := r.(interface{ Legend() string }); ok {
+		return withLegend.Legend()
+	}
+	return ""
+}
+
+// TileRendererVariable is the environment variable that chooses how the DefaultTileRenderer
+// draws the tiles: "symbols" for the SymbolRenderer, "names" for the PlainRenderer, or
+// "colors", which is the same as leaving it out.
+const TileRendererVariable = "AZUL_TILES"
+
+// DefaultTileRenderer returns the renderer chosen with the AZUL_TILES environment variable, if
+// any. Otherwise it returns the ColorRenderer if the output is a terminal that shows colors,
+// or the PlainRenderer if it isn't. Colors are turned off by setting the NO_COLOR environment
+// variable to anything but an empty string (see https://no-color.org), and they're never
+// written to a file or a pipe, or to a terminal that says it's "dumb".
+func DefaultTileRenderer(out *os.File) TileRenderer {
+	switch os.Getenv(TileRendererVariable) {
+	case "symbols":
+		return SymbolRenderer{}
+	case "names":
+		return PlainRenderer{}
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return PlainRenderer{}
+	}
+	info, err := out.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return PlainRenderer{}
+	}
+	return ColorRenderer{}
+}
+
+// renderTiles draws the tiles in brackets, for example for a factory
+func renderTiles(r TileRenderer, tiles []models.Tile) string {
+	rendered := make([]string, len(tiles))
+	for i, tile := range tiles {
+		rendered[i] = r.Tile(tile.Color)
+	}
+	return "[" + strings.Join(rendered, " ") + "]"
+}
@@ -0,0 +1,130 @@
+package interactions
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func TestTileRenderers(t *testing.T) {
+	testCases := map[string]struct {
+		renderer         TileRenderer
+		expectedTile     string
+		expectedWall     string
+		expectedFullWall string
+		expectedEmpty    string
+	}{
+		"Plain": {
+			renderer:         PlainRenderer{},
+			expectedTile:     "{  blue}",
+			expectedWall:     "{  blue [ ]}",
+			expectedFullWall: "{  blue [x]}",
+			expectedEmpty:    "{ empty}",
+		},
+		"Color": {
+			renderer:         ColorRenderer{},
+			expectedTile:     "\x1b[38;5;33m██\x1b[0m",
+			expectedWall:     "\x1b[2m\x1b[38;5;33m░░\x1b[0m",
+			expectedFullWall: "\x1b[38;5;33m██\x1b[0m",
+			expectedEmpty:    "\x1b[2m··\x1b[0m",
+		},
+		"Symbol": {
+			renderer:         SymbolRenderer{},
+			expectedTile:     "B",
+			expectedWall:     "b",
+			expectedFullWall: "B",
+			expectedEmpty:    ".",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			space := models.WallSpace{Tile: models.Tile{Color: models.Blue}}
+
+			assert.So(tc.renderer.Tile(models.Blue), should.Equal, tc.expectedTile)
+			assert.So(tc.renderer.WallSpace(space), should.Equal, tc.expectedWall)
+			space.HasTile = true
+			assert.So(tc.renderer.WallSpace(space), should.Equal, tc.expectedFullWall)
+			assert.So(tc.renderer.EmptySpace(), should.Equal, tc.expectedEmpty)
+		})
+	}
+}
+
+func TestDefaultTileRenderer(t *testing.T) {
+	assert := assertions.New(t)
+	file, err := ioutil.TempFile(t.TempDir(), "output")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// A file isn't a terminal
+	assert.So(DefaultTileRenderer(file), should.Resemble, PlainRenderer{})
+
+	// The symbols are plain text, so they're used wherever they're asked for
+	os.Setenv(TileRendererVariable, "symbols")
+	assert.So(DefaultTileRenderer(file), should.Resemble, SymbolRenderer{})
+	os.Unsetenv(TileRendererVariable)
+
+	if terminal, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer terminal.Close()
+		os.Setenv("NO_COLOR", "1")
+		defer os.Unsetenv("NO_COLOR")
+		assert.So(DefaultTileRenderer(terminal), should.Resemble, PlainRenderer{})
+	}
+}
+
+func TestSession_DisplayGameState_Color(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	setFactories(game, map[int][]models.Tile{0: {{Color: models.Red}}})
+	var output bytes.Buffer
+	s := NewSession(strings.NewReader(""), &output, WithTileRenderer(ColorRenderer{}))
+
+	s.DisplayGameState(game)
+	assert.So(output.String(), should.ContainSubstring, "Factory #0: [\x1b[38;5;160m██\x1b[0m]")
+	assert.So(output.String(), should.ContainSubstring, "Center of the Table: [\x1b[30;47m1 \x1b[0m]")
+	assert.So(output.String(), should.NotContainSubstring, "empty")
+}
+
+func TestSymbolRenderer_EveryColor(t *testing.T) {
+	assert := assertions.New(t)
+	symbols := make(map[string]models.TileColor)
+	for _, color := range append(models.DefaultGameConfig.TileColors, models.FirstPlayerTile) {
+		symbol := SymbolRenderer{}.Tile(color)
+		assert.So(symbols, should.NotContainKey, symbol)
+		assert.So(VisibleWidth(symbol), should.Equal, 1)
+		symbols[symbol] = color
+	}
+	assert.So(SymbolRenderer{}.Tile(models.FirstPlayerTile), should.Equal, "★")
+}
+
+func TestSession_DisplayGameState_Symbols(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	setFactories(game, map[int][]models.Tile{0: {{Color: models.Black}, {Color: models.Blue}}})
+	var output bytes.Buffer
+	s := NewSession(strings.NewReader("help\n"), &output, WithTileRenderer(SymbolRenderer{}))
+
+	s.DisplayGameState(game)
+	assert.So(output.String(), should.ContainSubstring, "Factory #0: [K B]")
+	assert.So(output.String(), should.ContainSubstring, "Center of the Table: [★]")
+	assert.So(output.String(), should.ContainSubstring, "\n b o r k w\n")
+	assert.So(output.String(), should.ContainSubstring, "Floor: .(-1) .(-1)")
+	assert.So(output.String(), should.ContainSubstring, "Tiles: B blue")
+
+	// The legend is only shown with the game the first time, and with the help
+	output.Reset()
+	s.DisplayGameState(game)
+	assert.So(output.String(), should.NotContainSubstring, "Tiles: B blue")
+	s.PromptForString("Which one?")
+	assert.So(output.String(), should.ContainSubstring, "Tiles: B blue")
+}
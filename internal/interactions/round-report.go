@@ -0,0 +1,103 @@
+package interactions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// playerRound is what happened to a player's board when the round was scored, collected from
+// the game's events until the round ends
+type playerRound struct {
+	name    string
+	tilings []models.WallTiled
+	floor   models.FloorScored
+}
+
+// collectRoundScoring adds a WallTiled or FloorScored event to the report of the round
+func (s *Session) collectRoundScoring(event models.Event) {
+	if s.round == nil {
+		s.round = make(map[int]*playerRound)
+	}
+	round := func(player int, name string) *playerRound {
+		if _, ok := s.round[player]; !ok {
+			s.round[player] = &playerRound{name: name}
+		}
+		return s.round[player]
+	}
+
+	switch e := event.(type) {
+	case models.WallTiled:
+		r := round(e.Player, e.PlayerName)
+		r.tilings = append(r.tilings, e)
+	case models.FloorScored:
+		round(e.Player, e.PlayerName).floor = e
+	}
+}
+
+// displayRoundReport prints how each player's board was scored at the end of the round: which
+// pattern lines moved to the wall and what each new tile was worth, what the floor cost, and
+// the player's score before and after. Then it says who has the first player tile.
+func (s *Session) displayRoundReport(e models.RoundEnded) {
+	fmt.Fprintf(s.writer, "ROUND %d IS OVER\n", e.Round)
+
+	players := make([]int, 0, len(s.round))
+	for player := range s.round {
+		players = append(players, player)
+	}
+	sort.Ints(players)
+
+	for _, player := range players {
+		r := s.round[player]
+		wallPoints := 0
+		for _, tiling := range r.tilings {
+			wallPoints += tiling.WallScore.Score
+		}
+		fmt.Fprintf(s.writer, "%s: %d point(s), from %d (%+d from the wall, %d from the floor)\n",
+			r.name, r.floor.ScoreAfter, r.floor.ScoreBefore, wallPoints, r.floor.Penalty)
+		if r.floor.ScoreBefore+wallPoints+r.floor.Penalty < r.floor.ScoreAfter {
+			fmt.Fprintln(s.writer, "  The score can't go below 0")
+		}
+
+		if len(r.tilings) == 0 {
+			fmt.Fprintln(s.writer, "  No pattern lines were full")
+		}
+		for _, tiling := range r.tilings {
+			fmt.Fprintf(s.writer, "  Pattern line #%d: %s\n", tiling.PatternLineNumber, describeWallScore(tiling))
+		}
+		if len(r.floor.Tiles) > 0 {
+			fmt.Fprintf(s.writer, "  Floor: %s for %d point(s)\n", renderTiles(s.renderer, r.floor.Tiles), r.floor.Penalty)
+		}
+	}
+
+	firstPlayer := fmt.Sprintf("Player #%d", e.FirstPlayer)
+	if r, ok := s.round[e.FirstPlayer]; ok {
+		firstPlayer = r.name
+	}
+	fmt.Fprintf(s.writer, "%s has the first player tile, and goes first if there's another round\n", firstPlayer)
+	s.round = nil
+}
+
+// describeWallScore says where the tile went on the wall and what it was worth. A tile on its
+// own is worth 1 point. Otherwise, it scores the tiles it's next to in its row and column, which
+// are the rest of the wall score's tiles.
+func describeWallScore(tiling models.WallTiled) string {
+	score := tiling.WallScore
+	if len(score.Tiles) == 0 {
+		return fmt.Sprintf("%s tile to the wall, %d point(s)", string(tiling.Tile.Color), score.Score)
+	}
+
+	placed := score.Tiles[0]
+	description := fmt.Sprintf("%s tile to row %d, column %d of the wall, %d point(s)",
+		string(tiling.Tile.Color), placed.Row, placed.Col, score.Score)
+	if len(score.Tiles) == 1 {
+		return description
+	}
+	linked := make([]string, len(score.Tiles)-1)
+	for i, coord := range score.Tiles[1:] {
+		linked[i] = fmt.Sprintf("(%d, %d)", coord.Row, coord.Col)
+	}
+	return fmt.Sprintf("%s with the tiles at %s", description, strings.Join(linked, " "))
+}
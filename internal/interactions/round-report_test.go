@@ -0,0 +1,70 @@
+package interactions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func TestSession_DisplayEvent_RoundReport(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	setFactories(game, map[int][]models.Tile{
+		0: {{Color: models.Blue}},
+		1: {{Color: models.Red}},
+	})
+	game.CenterOfTheTable.AddTile(models.Tile{Color: models.White})
+	var output bytes.Buffer
+	s := NewSession(strings.NewReader(""), &output, WithTileRenderer(SymbolRenderer{}))
+	game.Subscribe(models.ObserverFunc(s.DisplayEvent))
+
+	assert.So(game.TakeTurn(models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 0, TileColor: models.Blue, PatternLineNumber: 0}), should.BeNil)
+	assert.So(game.TakeTurn(models.Move{DrawSourceType: models.DrawSourceCenter, TileColor: models.White, PatternLineNumber: 0}), should.BeNil)
+	output.Reset()
+	assert.So(game.TakeTurn(models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Red, PatternLineNumber: 1}), should.BeNil)
+
+	assert.So(output.String(), should.ContainSubstring, `ROUND 1 IS OVER
+alice: 1 point(s), from 0 (+1 from the wall, 0 from the floor)
+  Pattern line #0: blue tile to row 0, column 0 of the wall, 1 point(s)
+bob: 0 point(s), from 0 (+1 from the wall, -1 from the floor)
+  Pattern line #0: white tile to row 0, column 4 of the wall, 1 point(s)
+  Floor: [★] for -1 point(s)
+bob has the first player tile, and goes first if there's another round
+`)
+}
+
+func TestSession_DisplayEvent_RoundReport_Scoring(t *testing.T) {
+	assert := assertions.New(t)
+	var output bytes.Buffer
+	s := NewSession(strings.NewReader(""), &output)
+
+	s.DisplayEvent(models.WallTiled{
+		Player: 1, PlayerName: "bob", PatternLineNumber: 1, Tile: models.Tile{Color: models.Red},
+		WallScore: models.WallScore{Score: 4, Tiles: []models.WallCoordinate{{Row: 1, Col: 3}, {Row: 1, Col: 2}, {Row: 0, Col: 3}}},
+	})
+	s.DisplayEvent(models.FloorScored{Player: 1, PlayerName: "bob", ScoreBefore: 1, Penalty: -8, ScoreAfter: 0,
+		Tiles: []models.Tile{{Color: models.Black}, {Color: models.Black}, {Color: models.Black}, {Color: models.Black}, {Color: models.Black}}})
+	s.DisplayEvent(models.FloorScored{Player: 0, PlayerName: "alice", ScoreBefore: 10, ScoreAfter: 10})
+	assert.So(output.String(), should.BeEmpty)
+
+	s.DisplayEvent(models.RoundEnded{Round: 3, Scores: map[int]int{0: 10, 1: 0}, FirstPlayer: 1})
+	assert.So(output.String(), should.Equal, `ROUND 3 IS OVER
+alice: 10 point(s), from 10 (+0 from the wall, 0 from the floor)
+  No pattern lines were full
+bob: 0 point(s), from 1 (+4 from the wall, -8 from the floor)
+  The score can't go below 0
+  Pattern line #1: red tile to row 1, column 3 of the wall, 4 point(s) with the tiles at (1, 2) (0, 3)
+  Floor: [{ black} { black} { black} { black} { black}] for -8 point(s)
+bob has the first player tile, and goes first if there's another round
+`)
+
+	// The next round's report starts over
+	output.Reset()
+	s.DisplayEvent(models.RoundEnded{Round: 4, FirstPlayer: 0})
+	assert.So(output.String(), should.Equal, "ROUND 4 IS OVER\nPlayer #0 has the first player tile, and goes first if there's another round\n")
+}
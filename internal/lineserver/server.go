@@ -0,0 +1,85 @@
+package lineserver
+
+import (
+	"net"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+)
+
+// Server hosts games over a plain-text TCP protocol, so they can be played with nc, or by a bot
+// written in any language that can read and write lines. The games are held by a
+// manager.Manager, which can be shared with the HTTP server.
+//
+// The client sends one command per line, and the server answers every command with any number
+// of data lines followed by a line that starts with "OK" or "ERR <message>". Notifications can
+// arrive at any time, and they're the lines that start with "*":
+//
+//	NOTIFICATION                 MEANING
+//	* MOVE <seat> <move>         a player made a move
+//	* TURN <seat> <name>         it's the player's turn
+//	* YOURTURN                   it's the turn of the seat this connection joined
+//	* ROUNDEND <round>           the round is over and the walls have been tiled
+//	* GAMEOVER <seat>:<score>    the game is over, and the scores of the winners
+//	* RESYNC                     the connection fell behind and missed notifications, so
+//	                             STATE should be sent to catch up
+//
+// The commands are:
+//
+//	COMMAND                      MEANING
+//	GAMES                        list the games
+//	CREATE <players>             create a game, and get its ID and the creator's token
+//	JOIN <game-id> <seat> <name> join a seat, and get its token
+//	WATCH <game-id>              follow a game without playing
+//	STATE                        get the state of the game
+//	MOVES                        list the current player's legal moves
+//	MOVE <move>                  make a move, for example "MOVE F2:blue>L3"
+//	WAIT                         wait until it's your turn, or the game is over
+//	RESIGN [bot]                 leave the game, and forfeit or hand the seat to a bot
+//	HELP                         list the commands
+//	QUIT                         close the connection
+type Server struct {
+	manager *manager.Manager
+}
+
+type NewServerOption func(s *Server)
+
+// WithManager sets the manager that holds the server's games, so they can be shared with
+// other servers. A new manager is used if it isn't set.
+func WithManager(m *manager.Manager) NewServerOption {
+	return func(s *Server) {
+		s.manager = m
+	}
+}
+
+func NewServer(opts ...NewServerOption) *Server {
+	s := &Server{
+		manager: manager.NewManager(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ListenAndServe listens on the TCP address and serves the connections until listening fails
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves each connection the listener accepts in its own goroutine, until the listener
+// fails or is closed
+func (s *Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go newSession(s.manager, conn).run()
+	}
+}
@@ -0,0 +1,275 @@
+package lineserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// testClient is a connection to the server that reads responses and notifications separately
+type testClient struct {
+	t       *testing.T
+	conn    net.Conn
+	scanner *bufio.Scanner
+	// notifications holds the notification lines that have been read but not checked yet
+	notifications []string
+}
+
+func dial(t *testing.T, addr string) *testClient {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testClient{t: t, conn: conn, scanner: bufio.NewScanner(conn)}
+	c.waitFor("* WELCOME")
+	return c
+}
+
+func (c *testClient) readLine() string {
+	if err := c.conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		c.t.Fatal(err)
+	}
+	if !c.scanner.Scan() {
+		c.t.Fatalf("the connection was closed: %v", c.scanner.Err())
+	}
+	return c.scanner.Text()
+}
+
+// send sends the command and returns the lines of its response, the last of which starts with
+// OK or ERR
+func (c *testClient) send(command string) []string {
+	if _, err := fmt.Fprintln(c.conn, command); err != nil {
+		c.t.Fatal(err)
+	}
+
+	var lines []string
+	for {
+		line := c.readLine()
+		if strings.HasPrefix(line, "*") {
+			c.notifications = append(c.notifications, line)
+			continue
+		}
+		lines = append(lines, line)
+		if strings.HasPrefix(line, "OK") || strings.HasPrefix(line, "ERR") {
+			return lines
+		}
+	}
+}
+
+// waitFor reads until it gets a notification that starts with the prefix
+func (c *testClient) waitFor(prefix string) {
+	for i, line := range c.notifications {
+		if strings.HasPrefix(line, prefix) {
+			c.notifications = c.notifications[i+1:]
+			return
+		}
+	}
+	c.notifications = nil
+
+	for {
+		if line := c.readLine(); strings.HasPrefix(line, prefix) {
+			return
+		}
+	}
+}
+
+func startTestServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go NewServer().Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String()
+}
+
+func TestServer_PlayGame(t *testing.T) {
+	assert := assertions.New(t)
+	addr := startTestServer(t)
+
+	alice := dial(t, addr)
+	defer alice.conn.Close()
+	bob := dial(t, addr)
+	defer bob.conn.Close()
+
+	created := strings.Fields(alice.send("CREATE 2")[0])
+	assert.So(created, should.HaveLength, 3)
+	gameID := created[1]
+
+	assert.So(alice.send("MOVE F0:blue>L0")[0], should.StartWith, "ERR")
+	assert.So(alice.send("JOIN " + gameID + " 0 alice")[0], should.StartWith, "OK 0 ")
+	assert.So(bob.send("JOIN " + gameID + " 0 bob")[0], should.StartWith, "ERR")
+	assert.So(bob.send("JOIN " + gameID + " 1 bob smith")[0], should.StartWith, "OK 1 ")
+
+	alice.waitFor("* YOURTURN")
+	state := alice.send("STATE")
+	assert.So(state[0], should.Equal, fmt.Sprintf("GAME %s playing ROUND 1 MOVES 0", gameID))
+	assert.So(state[1], should.Equal, "TURN 0 alice")
+	assert.So(state, should.Contain, "PLAYER 1 0 bob smith")
+	assert.So(state, should.Contain, "LINE 0 0")
+	assert.So(state, should.Contain, "WALL 1 4 . . . . .")
+	assert.So(state[len(state)-1], should.Equal, "OK")
+
+	moves := strings.Fields(alice.send("MOVES")[0])
+	assert.So(moves[0], should.Equal, "OK")
+	assert.So(len(moves), should.BeGreaterThan, 1)
+	move := moves[1]
+
+	assert.So(bob.send("MOVE " + move)[0], should.StartWith, "ERR it's alice's turn")
+	assert.So(alice.send("MOVE F9:blue>L0")[0], should.StartWith, "ERR")
+	assert.So(alice.send("MOVE "+move), should.Resemble, []string{"OK"})
+	bob.waitFor("* MOVE 0 " + move)
+	bob.waitFor("* YOURTURN")
+	assert.So(bob.send("WAIT"), should.Resemble, []string{"OK YOURTURN"})
+
+	// alice's WAIT only returns once bob has moved
+	waited := make(chan []string)
+	go func() { waited <- alice.send("WAIT") }()
+	moves = strings.Fields(bob.send("MOVES")[0])
+	assert.So(bob.send("MOVE "+moves[1]), should.Resemble, []string{"OK"})
+	select {
+	case response := <-waited:
+		assert.So(response, should.Resemble, []string{"OK YOURTURN"})
+	case <-time.After(5 * time.Second):
+		t.Fatal("WAIT didn't return")
+	}
+}
+
+func TestServer_Commands(t *testing.T) {
+	testCases := map[string]struct {
+		command          string
+		expectedResponse string
+	}{
+		"Unknown command":         {command: "DANCE", expectedResponse: "ERR 'DANCE' is not a command, send HELP for the list of commands"},
+		"Commands ignore case":    {command: "games", expectedResponse: "OK"},
+		"Create without players":  {command: "CREATE", expectedResponse: "ERR usage: CREATE <players>"},
+		"Create too many players": {command: "CREATE 9", expectedResponse: "ERR"},
+		"Join a missing game":     {command: "JOIN 42 0 alice", expectedResponse: "ERR game '42' was not found"},
+		"State without a game":    {command: "STATE", expectedResponse: "ERR join or watch a game first"},
+		"Wait without a game":     {command: "WAIT", expectedResponse: "ERR join or watch a game first"},
+		"Move without joining":    {command: "MOVE F0:blue>L0", expectedResponse: "ERR join a game before making moves"},
+		"Quit closes the session": {command: "QUIT", expectedResponse: "OK BYE"},
+	}
+
+	addr := startTestServer(t)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			c := dial(t, addr)
+			defer c.conn.Close()
+
+			response := c.send(tc.command)
+			assert.So(response[len(response)-1], should.StartWith, tc.expectedResponse)
+		})
+	}
+}
+
+func TestSession_FallsBehind(t *testing.T) {
+	assert := assertions.New(t)
+	m := manager.NewManager()
+	game, creatorToken, err := m.Create(models.DefaultGameConfig, 2, nil, nil)
+	assert.So(err, should.BeNil)
+	_, aliceToken, err := game.Join(0, "alice")
+	assert.So(err, should.BeNil)
+	_, err = game.ReplaceWithBot(creatorToken, 1, "greedy")
+	assert.So(err, should.BeNil)
+
+	// A pipe has no buffer, so the session falls behind as soon as the client stops reading
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	go newSession(m, serverConn).run()
+	c := &testClient{t: t, conn: clientConn, scanner: bufio.NewScanner(clientConn)}
+	c.waitFor("* WELCOME")
+	assert.So(c.send("WATCH "+game.ID), should.Resemble, []string{"OK"})
+
+	for i := 0; i < 30 && game.State().Status == manager.GameStatusPlaying; i++ {
+		_, moves := game.LegalMoves()
+		_, err := game.MakeMove(aliceToken, moves[0])
+		assert.So(err, should.BeNil)
+	}
+
+	// The session follows the game again, so WAIT still works
+	c.waitFor("* RESYNC")
+	lines := make(chan string, 10000)
+	go func() {
+		for {
+			line := c.readLine()
+			lines <- line
+			if !strings.HasPrefix(line, "*") {
+				return
+			}
+		}
+	}()
+	for game.State().Status == manager.GameStatusPlaying {
+		_, moves := game.LegalMoves()
+		_, err := game.MakeMove(aliceToken, moves[0])
+		assert.So(err, should.BeNil)
+	}
+	fmt.Fprintln(clientConn, "WAIT")
+	for line := range lines {
+		if !strings.HasPrefix(line, "*") {
+			assert.So(line, should.Equal, "OK GAMEOVER")
+			break
+		}
+	}
+}
+
+func TestSession_GameOverLeavesOutForfeitedSeats(t *testing.T) {
+	assert := assertions.New(t)
+	m := manager.NewManager()
+	seed := int64(3)
+	game, _, err := m.Create(models.DefaultGameConfig, 2, &seed, nil)
+	assert.So(err, should.BeNil)
+	_, aliceToken, err := game.Join(0, "alice")
+	assert.So(err, should.BeNil)
+	_, bobToken, err := game.Join(1, "bob")
+	assert.So(err, should.BeNil)
+	_, err = game.Resign(bobToken, "")
+	assert.So(err, should.BeNil)
+
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	go newSession(m, serverConn).run()
+	c := &testClient{t: t, conn: clientConn, scanner: bufio.NewScanner(clientConn)}
+	c.waitFor("* WELCOME")
+	assert.So(c.send("WATCH "+game.ID), should.Resemble, []string{"OK"})
+
+	// alice throws every tile on her floor, so bob's random moves score more than she does,
+	// but bob forfeited and can't win
+	var gameOver string
+	for moves := 0; gameOver == "" && moves < 2000; moves++ {
+		_, legalMoves := game.LegalMoves()
+		for _, move := range legalMoves {
+			if move.PatternLineNumber == models.FloorLine {
+				_, err := game.MakeMove(aliceToken, move)
+				assert.So(err, should.BeNil)
+				break
+			}
+		}
+		// Keep up with the notifications, so the session doesn't fall behind
+		for {
+			line := c.readLine()
+			if strings.HasPrefix(line, "* GAMEOVER") {
+				gameOver = line
+				break
+			}
+			if strings.HasPrefix(line, "* TURN 0 ") {
+				break
+			}
+		}
+	}
+
+	state := game.State()
+	assert.So(state.Winners, should.Resemble, []int{0})
+	assert.So(state.State.Players[1].Board.Score, should.BeGreaterThan, state.State.Players[0].Board.Score)
+	assert.So(gameOver, should.Equal, fmt.Sprintf("* GAMEOVER 0:%d", state.State.Players[0].Board.Score))
+}
@@ -0,0 +1,495 @@
+package lineserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// noSeat is the seat of a connection that's watching a game, or hasn't joined one
+const noSeat = -1
+
+// session is one client's connection. Commands are handled one at a time by run, while the
+// game's notifications are written by another goroutine, so every write holds writeMu. The
+// seat, the last turn that was announced and the subscription are used by both goroutines,
+// so they're guarded by writeMu as well.
+type session struct {
+	manager *manager.Manager
+	conn    net.Conn
+
+	writeMu      sync.Mutex
+	writer       *bufio.Writer
+	seat         int
+	lastTurn     turn
+	toldYourTurn bool
+
+	game  *manager.Game
+	token string
+	sub   *manager.Subscription
+	// changed is signalled whenever the game's state changes, to wake up WAIT
+	changed chan struct{}
+}
+
+func newSession(m *manager.Manager, conn net.Conn) *session {
+	return &session{
+		manager: m,
+		conn:    conn,
+		writer:  bufio.NewWriter(conn),
+		seat:    noSeat,
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// run reads and handles the client's commands until the client quits or disconnects
+func (s *session) run() {
+	defer s.close()
+
+	s.write("* WELCOME to azul, send HELP for the list of commands")
+
+	scanner := bufio.NewScanner(s.conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		command := strings.ToUpper(fields[0])
+		if command == "QUIT" {
+			s.write("OK BYE")
+			return
+		}
+		lines, err := s.handle(command, fields[1:])
+		if err != nil {
+			lines = []string{fmt.Sprintf("ERR %s", err)}
+		}
+		s.write(lines...)
+	}
+}
+
+func (s *session) close() {
+	s.cancelSubscription()
+	s.conn.Close()
+}
+
+// write sends the lines to the client together, so they aren't split by a notification
+func (s *session) write(lines ...string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.writeLocked(lines...)
+}
+
+// writeLocked sends the lines to the client. It must be called while holding writeMu.
+func (s *session) writeLocked(lines ...string) {
+	for _, line := range lines {
+		s.writer.WriteString(line)
+		s.writer.WriteString("\n")
+	}
+	if err := s.writer.Flush(); err != nil {
+		// The client is gone, so closing the connection stops run as well
+		s.conn.Close()
+	}
+}
+
+// handle runs the command, and returns the lines of the response
+func (s *session) handle(command string, args []string) ([]string, error) {
+	switch command {
+	case "HELP":
+		return helpLines, nil
+	case "GAMES":
+		return s.handleGames()
+	case "CREATE":
+		return s.handleCreate(args)
+	case "JOIN":
+		return s.handleJoin(args)
+	case "WATCH":
+		return s.handleWatch(args)
+	case "STATE":
+		return s.handleState()
+	case "MOVES":
+		return s.handleMoves()
+	case "MOVE":
+		return s.handleMove(args)
+	case "WAIT":
+		return s.handleWait()
+	case "RESIGN":
+		return s.handleResign(args)
+	default:
+		return nil, fmt.Errorf("'%s' is not a command, send HELP for the list of commands", command)
+	}
+}
+
+var helpLines = []string{
+	"GAMES                        list the games",
+	"CREATE <players>             create a game, and get its ID and the creator's token",
+	"JOIN <game-id> <seat> <name> join a seat, and get its token",
+	"WATCH <game-id>              follow a game without playing",
+	"STATE                        get the state of the game",
+	"MOVES                        list the current player's legal moves",
+	"MOVE <move>                  make a move, for example MOVE F2:blue>L3",
+	"WAIT                         wait until it's your turn, or the game is over",
+	"RESIGN [bot]                 leave the game, and forfeit or hand the seat to a bot",
+	"QUIT                         close the connection",
+	"OK",
+}
+
+func (s *session) handleGames() ([]string, error) {
+	var lines []string
+	for _, summary := range s.manager.List() {
+		names := make([]string, len(summary.Seats))
+		for i, seat := range summary.Seats {
+			names[i] = seat.Name
+		}
+		lines = append(lines, fmt.Sprintf("GAME %s %s %s", summary.ID, summary.Status, strings.Join(names, ", ")))
+	}
+	return append(lines, "OK"), nil
+}
+
+func (s *session) handleCreate(args []string) ([]string, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("usage: CREATE <players>")
+	}
+	numPlayers, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a number of players", args[0])
+	}
+
+	game, creatorToken, err := s.manager.Create(models.DefaultGameConfig, numPlayers, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("OK %s %s", game.ID, creatorToken)}, nil
+}
+
+func (s *session) handleJoin(args []string) ([]string, error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("usage: JOIN <game-id> <seat> <name>")
+	}
+	if s.game != nil {
+		return nil, fmt.Errorf("this connection is already following game '%s'", s.game.ID)
+	}
+	game, err := s.manager.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+	seat, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a seat", args[1])
+	}
+
+	// Subscribing first means nothing is missed between joining and following the game
+	s.follow(game)
+	_, token, err := game.Join(seat, strings.Join(args[2:], " "))
+	if err != nil {
+		s.unfollow()
+		return nil, err
+	}
+	s.writeMu.Lock()
+	s.seat = seat
+	s.writeMu.Unlock()
+	s.token = token
+	s.notifyTurn(game.State())
+
+	return []string{fmt.Sprintf("OK %d %s", seat, token)}, nil
+}
+
+func (s *session) handleWatch(args []string) ([]string, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("usage: WATCH <game-id>")
+	}
+	if s.game != nil {
+		return nil, fmt.Errorf("this connection is already following game '%s'", s.game.ID)
+	}
+	game, err := s.manager.Get(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s.follow(game)
+	return []string{"OK"}, nil
+}
+
+func (s *session) handleState() ([]string, error) {
+	if s.game == nil {
+		return nil, errNoGame
+	}
+	return append(stateLines(s.game.State()), "OK"), nil
+}
+
+func (s *session) handleMoves() ([]string, error) {
+	if s.game == nil {
+		return nil, errNoGame
+	}
+	_, moves := s.game.LegalMoves()
+
+	notations := make([]string, len(moves))
+	for i, move := range moves {
+		notations[i] = move.String()
+	}
+	return []string{strings.TrimSpace("OK " + strings.Join(notations, " "))}, nil
+}
+
+func (s *session) handleMove(args []string) ([]string, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("usage: MOVE <move>, for example MOVE F2:blue>L3")
+	}
+	if s.seat == noSeat {
+		return nil, fmt.Errorf("join a game before making moves")
+	}
+	move, err := models.ParseMove(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.game.MakeMove(s.token, move); err != nil {
+		return nil, err
+	}
+	return []string{"OK"}, nil
+}
+
+// handleResign leaves the game. The connection keeps following the game as a spectator.
+func (s *session) handleResign(args []string) ([]string, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("usage: RESIGN [bot]")
+	}
+	if s.seat == noSeat {
+		return nil, fmt.Errorf("join a game before resigning")
+	}
+	var botName string
+	if len(args) == 1 {
+		botName = args[0]
+	}
+
+	if _, err := s.game.Resign(s.token, botName); err != nil {
+		return nil, err
+	}
+	s.writeMu.Lock()
+	s.seat = noSeat
+	s.writeMu.Unlock()
+	s.token = ""
+	return []string{"OK"}, nil
+}
+
+// handleWait blocks until it's the seat's turn or the game is over. Spectators wait until
+// the game is over.
+func (s *session) handleWait() ([]string, error) {
+	if s.game == nil {
+		return nil, errNoGame
+	}
+
+	for {
+		state := s.game.State()
+		if state.Status == manager.GameStatusFinished {
+			return []string{"OK GAMEOVER"}, nil
+		}
+		if state.Status == manager.GameStatusPlaying && state.CurrentPlayer == s.seat {
+			return []string{"OK YOURTURN"}, nil
+		}
+
+		if _, ok := <-s.changed; !ok {
+			return nil, fmt.Errorf("the connection to game '%s' was lost", s.game.ID)
+		}
+	}
+}
+
+var errNoGame = fmt.Errorf("join or watch a game first")
+
+// follow subscribes to the game, and sends its notifications to the client in the background
+func (s *session) follow(game *manager.Game) {
+	_, sub := game.Subscribe()
+	s.game = game
+	s.writeMu.Lock()
+	s.sub = sub
+	s.writeMu.Unlock()
+
+	changed := s.changed
+	go func() {
+		// WAIT gives up once the notifications stop
+		defer close(changed)
+
+		for {
+			for message := range sub.Messages {
+				if message.Type == manager.MessageTypeState {
+					s.notifyTurn(*message.Game)
+					signal(changed)
+					continue
+				}
+				if line, ok := notificationLine(message.Event); ok {
+					s.write(line)
+				}
+			}
+
+			var state manager.GameState
+			if sub, state = s.resubscribe(game, sub); sub == nil {
+				return
+			}
+			s.notifyTurn(state)
+			signal(changed)
+		}
+	}()
+}
+
+// resubscribe follows the game again after the subscription ended, unless the session ended
+// it itself. The game drops a subscriber that falls behind, and the client is told that it
+// missed some notifications. It returns nil if the session has stopped following the game.
+func (s *session) resubscribe(game *manager.Game, ended *manager.Subscription) (*manager.Subscription, manager.GameState) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if s.sub != ended {
+		return nil, manager.GameState{}
+	}
+	state, sub := game.Subscribe()
+	s.sub = sub
+	s.writeLocked("* RESYNC")
+	return sub, state
+}
+
+// cancelSubscription stops the game's notifications. The subscription is cleared first, so
+// the notifications goroutine knows it was ended on purpose.
+func (s *session) cancelSubscription() {
+	s.writeMu.Lock()
+	sub := s.sub
+	s.sub = nil
+	s.writeMu.Unlock()
+
+	if sub != nil {
+		sub.Cancel()
+	}
+}
+
+// unfollow stops following the game, when joining it failed
+func (s *session) unfollow() {
+	s.cancelSubscription()
+	// The notifications goroutine closes the channel once it's done, so a new one is needed
+	for range s.changed {
+	}
+	s.game, s.changed = nil, make(chan struct{}, 1)
+}
+
+// signal wakes up WAIT, unless it's already been woken up
+func signal(changed chan struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// turn identifies a turn, so it's only announced once even though the state is broadcast more
+// often than that
+type turn struct {
+	moveCount     int
+	currentPlayer int
+}
+
+// notifyTurn lets the client know whose turn it is, unless it already knows. Only the seat's
+// own connection gets the YOURTURN notification.
+func (s *session) notifyTurn(state manager.GameState) {
+	if state.Status != manager.GameStatusPlaying {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	current := turn{moveCount: state.MoveCount, currentPlayer: state.CurrentPlayer}
+	yourTurn := state.CurrentPlayer == s.seat
+	var lines []string
+	if current != s.lastTurn {
+		lines = append(lines, fmt.Sprintf("* TURN %d %s", state.CurrentPlayer, state.Seats[state.CurrentPlayer].Name))
+	}
+	if yourTurn && (current != s.lastTurn || !s.toldYourTurn) {
+		lines = append(lines, "* YOURTURN")
+	}
+	s.lastTurn, s.toldYourTurn = current, yourTurn
+	s.writeLocked(lines...)
+}
+
+// notificationLine describes the events that a client needs to follow a game
+func notificationLine(event models.Event) (string, bool) {
+	switch e := event.(type) {
+	case models.TilesDrawn:
+		return fmt.Sprintf("* MOVE %d %s", e.Player, e.Move), true
+	case models.RoundEnded:
+		return fmt.Sprintf("* ROUNDEND %d", e.Round), true
+	case models.GameEnded:
+		line := "* GAMEOVER"
+		for _, winner := range e.Winners {
+			line = fmt.Sprintf("%s %d:%d", line, winner, e.Scores[winner])
+		}
+		return line, true
+	}
+	return "", false
+}
+
+// stateLines describes the game one line at a time, in a format that's easy to parse:
+//
+//	GAME <id> <status> ROUND <round> MOVES <move count>
+//	TURN <seat> <name>
+//	FACTORY <number> <tiles>
+//	CENTER <tiles>
+//	PLAYER <seat> <score> <name>
+//	LINE <seat> <line> <tiles>
+//	WALL <seat> <row> <tiles, with "." for the empty spaces>
+//	FLOOR <seat> <tiles>
+func stateLines(state manager.GameState) []string {
+	snapshot := state.State
+	lines := []string{
+		fmt.Sprintf("GAME %s %s ROUND %d MOVES %d", state.ID, state.Status, snapshot.Round, state.MoveCount),
+		fmt.Sprintf("TURN %d %s", state.CurrentPlayer, state.Seats[state.CurrentPlayer].Name),
+	}
+
+	factoryNumbers := make([]int, 0, len(snapshot.Factories))
+	for number := range snapshot.Factories {
+		factoryNumbers = append(factoryNumbers, number)
+	}
+	sort.Ints(factoryNumbers)
+	for _, number := range factoryNumbers {
+		lines = append(lines, joinLine(fmt.Sprintf("FACTORY %d", number), tileColors(snapshot.Factories[number])))
+	}
+	lines = append(lines, joinLine("CENTER", tileColors(snapshot.CenterOfTheTable)))
+
+	for seat := 0; seat < len(snapshot.Players); seat++ {
+		player := snapshot.Players[seat]
+		lines = append(lines, fmt.Sprintf("PLAYER %d %d %s", seat, player.Board.Score, player.Name))
+		for line := 0; line < models.NumPatternLines; line++ {
+			lines = append(lines, joinLine(fmt.Sprintf("LINE %d %d", seat, line), tileColors(player.Board.PatternLines[line])))
+		}
+		for row, spaces := range player.Board.Wall {
+			colors := make([]string, len(spaces))
+			for i, space := range spaces {
+				colors[i] = "."
+				if space.HasTile {
+					colors[i] = string(space.Color)
+				}
+			}
+			lines = append(lines, joinLine(fmt.Sprintf("WALL %d %d", seat, row), colors))
+		}
+		floor := make([]models.Tile, len(player.Board.Floor))
+		for i, space := range player.Board.Floor {
+			floor[i] = space.Tile
+		}
+		lines = append(lines, joinLine(fmt.Sprintf("FLOOR %d", seat), tileColors(floor)))
+	}
+
+	return lines
+}
+
+func tileColors(tiles []models.Tile) []string {
+	colors := make([]string, len(tiles))
+	for i, tile := range tiles {
+		colors[i] = string(tile.Color)
+	}
+	return colors
+}
+
+func joinLine(prefix string, words []string) string {
+	return strings.TrimSpace(prefix + " " + strings.Join(words, " "))
+}
@@ -0,0 +1,266 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// TimeoutPolicy is what happens to a seat whose clock runs out
+type TimeoutPolicy string
+
+const (
+	// TimeoutPolicyForfeit forfeits the game for the seat. Its moves are made at random from
+	// then on, so the other players can finish the game, but the seat can't win.
+	TimeoutPolicyForfeit TimeoutPolicy = "forfeit"
+	// TimeoutPolicyRandom makes a random legal move for the seat. The clock stays at zero, so
+	// the seat only has its increment for each move after that. The seat forfeits if the
+	// random move can't be made.
+	TimeoutPolicyRandom TimeoutPolicy = "random"
+	// TimeoutPolicyBot hands the seat to a bot for the rest of the game
+	TimeoutPolicyBot TimeoutPolicy = "bot"
+)
+
+// ClockConfig sets up a clock for each seat. A seat's clock starts with Base, and it only runs
+// during the seat's turns. Increment is added to it after each of the seat's moves. The clocks
+// of bots don't run.
+//
+// In JSON, Base and Increment are duration strings like "5m" or "1m30s", or numbers of
+// milliseconds.
+type ClockConfig struct {
+	Base      time.Duration
+	Increment time.Duration
+	OnTimeout TimeoutPolicy
+	// Bot is the kind of bot that takes over with TimeoutPolicyBot, "greedy" if it's empty
+	Bot string `json:",omitempty"`
+}
+
+// clockConfigJSON is a ClockConfig as it's written in JSON
+type clockConfigJSON struct {
+	Base      json.RawMessage
+	Increment json.RawMessage `json:",omitempty"`
+	OnTimeout TimeoutPolicy
+	Bot       string `json:",omitempty"`
+}
+
+// MarshalJSON writes the times as duration strings
+func (c ClockConfig) MarshalJSON() ([]byte, error) {
+	raw := clockConfigJSON{
+		Base:      json.RawMessage(strconv.Quote(c.Base.String())),
+		Increment: json.RawMessage(strconv.Quote(c.Increment.String())),
+		OnTimeout: c.OnTimeout,
+		Bot:       c.Bot,
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON reads the times from duration strings or numbers of milliseconds
+func (c *ClockConfig) UnmarshalJSON(data []byte) error {
+	var raw clockConfigJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	base, err := parseJSONDuration("Base", raw.Base)
+	if err != nil {
+		return err
+	}
+	increment, err := parseJSONDuration("Increment", raw.Increment)
+	if err != nil {
+		return err
+	}
+
+	*c = ClockConfig{Base: base, Increment: increment, OnTimeout: raw.OnTimeout, Bot: raw.Bot}
+	return nil
+}
+
+// parseJSONDuration reads a duration string or a number of milliseconds. A missing time is zero.
+func parseJSONDuration(field string, raw json.RawMessage) (time.Duration, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return 0, models.InvalidActionError{Message: fmt.Sprintf("The clock's %s isn't a duration like \"5m\" or \"30s\": %s", field, err)}
+		}
+		return duration, nil
+	}
+	var milliseconds float64
+	if err := json.Unmarshal(raw, &milliseconds); err != nil {
+		return 0, models.InvalidActionError{Message: fmt.Sprintf("The clock's %s must be a duration like \"5m\", or a number of milliseconds", field)}
+	}
+	return time.Duration(milliseconds * float64(time.Millisecond)), nil
+}
+
+func (c ClockConfig) Validate() error {
+	if c.Base <= 0 {
+		return models.InvalidActionError{Message: "The clock's base time must be more than zero"}
+	}
+	if c.Increment < 0 {
+		return models.InvalidActionError{Message: "The clock's increment can't be negative"}
+	}
+	switch c.OnTimeout {
+	case TimeoutPolicyForfeit, TimeoutPolicyRandom:
+	case TimeoutPolicyBot:
+		if _, err := bots.New(c.timeoutBot()); err != nil {
+			return err
+		}
+	default:
+		return models.InvalidActionError{Message: fmt.Sprintf("'%s' is not a timeout policy, please choose one of %v", c.OnTimeout,
+			[]TimeoutPolicy{TimeoutPolicyForfeit, TimeoutPolicyRandom, TimeoutPolicyBot})}
+	}
+	return nil
+}
+
+func (c ClockConfig) timeoutBot() string {
+	if c.Bot == "" {
+		return "greedy"
+	}
+	return c.Bot
+}
+
+// ClockState is a seat's clock, as of when the game's state was taken
+type ClockState struct {
+	Remaining time.Duration
+	Running   bool
+}
+
+// clock keeps the time for each seat. Only the current player's clock can run, and only
+// while the game is being played and it's a person's turn.
+type clock struct {
+	config    ClockConfig
+	remaining []time.Duration
+	// running is the seat whose clock is running, or noSeat
+	running int
+	started time.Time
+	timer   *time.Timer
+	// turn counts the times a clock has been started, so a timer that fires after its turn
+	// is over can tell it's too late
+	turn int
+}
+
+const noSeat = -1
+
+func newClock(config ClockConfig, numSeats int) *clock {
+	c := &clock{
+		config:    config,
+		remaining: make([]time.Duration, numSeats),
+		running:   noSeat,
+	}
+	for i := range c.remaining {
+		c.remaining[i] = config.Base
+	}
+	return c
+}
+
+// states returns the time left on each seat's clock
+func (c *clock) states() []ClockState {
+	states := make([]ClockState, len(c.remaining))
+	for i, remaining := range c.remaining {
+		states[i] = ClockState{Remaining: remaining}
+	}
+	if c.running != noSeat {
+		states[c.running].Remaining = c.remainingNow()
+		states[c.running].Running = true
+	}
+	return states
+}
+
+func (c *clock) remainingNow() time.Duration {
+	remaining := c.remaining[c.running] - time.Since(c.started)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// stop charges the running clock for the time since it started, and returns the seat's
+// remaining time
+func (c *clock) stop() time.Duration {
+	if c.running == noSeat {
+		return 0
+	}
+	c.timer.Stop()
+
+	seat := c.running
+	c.remaining[seat] = c.remainingNow()
+	c.running = noSeat
+	return c.remaining[seat]
+}
+
+// syncClock starts the current player's clock if it should be running, and stops the clock
+// that's running if it shouldn't be. It must be called while holding the write lock, after
+// every change to the game.
+func (g *Game) syncClock() {
+	if g.clock == nil {
+		return
+	}
+
+	seat := noSeat
+	if _, isBot := g.bots[g.game.CurrentPlayerKey]; g.status() == GameStatusPlaying && !isBot {
+		seat = g.game.CurrentPlayerKey
+	}
+	if seat == g.clock.running {
+		return
+	}
+
+	g.clock.stop()
+	if seat == noSeat {
+		return
+	}
+
+	g.clock.turn++
+	turn := g.clock.turn
+	g.clock.running, g.clock.started = seat, time.Now()
+	g.clock.timer = time.AfterFunc(g.clock.remaining[seat], func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if g.clock.running == seat && g.clock.turn == turn {
+			g.clock.stop()
+			g.timeOut(seat)
+		}
+	})
+}
+
+// chargeMove stops the seat's clock for the move it's making, and adds the increment. It
+// returns false, without adding the increment, if the seat has run out of time.
+func (g *Game) chargeMove(seat int) bool {
+	if g.clock == nil || g.clock.running != seat {
+		return true
+	}
+	if g.clock.stop() <= 0 {
+		return false
+	}
+	g.clock.remaining[seat] += g.clock.config.Increment
+	return true
+}
+
+// timeOut applies the timeout policy to the seat, whose clock has run out. It must be called
+// while holding the write lock.
+func (g *Game) timeOut(seat int) {
+	switch g.clock.config.OnTimeout {
+	case TimeoutPolicyRandom:
+		if err := g.playRandomMove(); err != nil {
+			// The seat's clock has stopped, so the game would wait for the seat forever
+			g.logger.Printf("game %s: the random move for seat #%d failed, so the seat forfeits: %s", g.ID, seat, err)
+			g.forfeit(seat)
+			return
+		}
+		g.clock.remaining[seat] += g.clock.config.Increment
+		g.broadcastState()
+		g.playBots()
+	case TimeoutPolicyBot:
+		botName := g.clock.config.timeoutBot()
+		bot, _ := bots.New(botName)
+		g.handToBot(seat, botName, bot)
+	case TimeoutPolicyForfeit:
+		g.forfeit(seat)
+	}
+}
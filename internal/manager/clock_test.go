@@ -0,0 +1,191 @@
+package manager
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// createTimedGame creates a game for alice and bob with the clock, and returns the game and
+// the seats' tokens
+func createTimedGame(t *testing.T, clock ClockConfig) (*Game, []string) {
+	seed := int64(1)
+	game, _, err := NewManager().Create(models.DefaultGameConfig, 2, &seed, &clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := make([]string, 2)
+	for i, name := range []string{"alice", "bob"} {
+		if _, tokens[i], err = game.Join(i, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return game, tokens
+}
+
+// waitForState reads the subscription's states until one of them is what the test expects
+func waitForState(t *testing.T, sub *Subscription, expected func(state GameState) bool) GameState {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message := <-sub.Messages:
+			if message.Type == MessageTypeState && expected(*message.Game) {
+				return *message.Game
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the game's state")
+		}
+	}
+}
+
+func TestGame_ClockTimeout(t *testing.T) {
+	testCases := map[string]struct {
+		policy        TimeoutPolicy
+		expectedSeats []Seat
+	}{
+		"A random move is made": {
+			policy:        TimeoutPolicyRandom,
+			expectedSeats: []Seat{{Name: "alice", Joined: true}, {Name: "bob", Joined: true}},
+		},
+		"A bot takes over the seat": {
+			policy:        TimeoutPolicyBot,
+			expectedSeats: []Seat{{Name: "alice", Joined: true, Bot: "greedy"}, {Name: "bob", Joined: true}},
+		},
+		"The seat forfeits": {
+			policy:        TimeoutPolicyForfeit,
+			expectedSeats: []Seat{{Name: "alice", Joined: true, Forfeited: true}, {Name: "bob", Joined: true}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game, tokens := createTimedGame(t, ClockConfig{Base: 50 * time.Millisecond, Increment: time.Minute, OnTimeout: tc.policy})
+			_, sub := game.Subscribe()
+			defer sub.Cancel()
+
+			// alice doesn't move in time, so the game moves on to bob
+			state := waitForState(t, sub, func(state GameState) bool { return state.CurrentPlayer == 1 })
+			assert.So(state.MoveCount, should.Equal, 1)
+			assert.So(state.Seats, should.Resemble, tc.expectedSeats)
+			assert.So(state.Clocks[0].Running, should.BeFalse)
+			assert.So(state.Clocks[1].Running, should.BeTrue)
+
+			// The game goes on after bob's move. alice is still playing, unless the seat is now
+			// played by a bot, which moves straight away.
+			_, moves := game.LegalMoves()
+			state, err := game.MakeMove(tokens[1], moves[0])
+			assert.So(err, should.BeNil)
+			if tc.policy == TimeoutPolicyRandom {
+				assert.So(state.CurrentPlayer, should.Equal, 0)
+				assert.So(state.Clocks[0].Running, should.BeTrue)
+			} else {
+				assert.So(state.MoveCount, should.Equal, 3)
+				assert.So(state.CurrentPlayer, should.Equal, 1)
+			}
+		})
+	}
+}
+
+func TestGame_ClockIncrement(t *testing.T) {
+	assert := assertions.New(t)
+	game, tokens := createTimedGame(t, ClockConfig{Base: time.Minute, Increment: 10 * time.Second, OnTimeout: TimeoutPolicyForfeit})
+
+	state := game.State()
+	assert.So(state.Clock.Base, should.Equal, time.Minute)
+	assert.So(state.Clocks[0].Running, should.BeTrue)
+	assert.So(state.Clocks[1], should.Resemble, ClockState{Remaining: time.Minute})
+
+	_, moves := game.LegalMoves()
+	state, err := game.MakeMove(tokens[0], moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(state.Clocks[0].Running, should.BeFalse)
+	assert.So(state.Clocks[0].Remaining, should.BeGreaterThan, time.Minute)
+	assert.So(state.Clocks[0].Remaining, should.BeLessThanOrEqualTo, time.Minute+10*time.Second)
+	assert.So(state.Clocks[1].Running, should.BeTrue)
+}
+
+func TestClockConfig_Validate(t *testing.T) {
+	testCases := map[string]struct {
+		config        ClockConfig
+		expectedError error
+	}{
+		"A valid clock": {
+			config:        ClockConfig{Base: time.Minute, OnTimeout: TimeoutPolicyBot, Bot: "random"},
+			expectedError: nil,
+		},
+		"Error case: no base time": {
+			config:        ClockConfig{OnTimeout: TimeoutPolicyForfeit},
+			expectedError: models.InvalidActionError{Message: "The clock's base time must be more than zero"},
+		},
+		"Error case: negative increment": {
+			config:        ClockConfig{Base: time.Minute, Increment: -time.Second, OnTimeout: TimeoutPolicyForfeit},
+			expectedError: models.InvalidActionError{Message: "The clock's increment can't be negative"},
+		},
+		"Error case: unknown policy": {
+			config:        ClockConfig{Base: time.Minute, OnTimeout: "nap"},
+			expectedError: models.InvalidActionError{Message: "'nap' is not a timeout policy, please choose one of [forfeit random bot]"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			assert.So(tc.config.Validate(), should.Resemble, tc.expectedError)
+		})
+	}
+}
+
+func TestClockConfig_JSON(t *testing.T) {
+	testCases := map[string]struct {
+		json           string
+		expectedConfig ClockConfig
+		expectedError  error
+	}{
+		"Duration strings": {
+			json:           `{"Base": "5m", "Increment": "2.5s", "OnTimeout": "forfeit"}`,
+			expectedConfig: ClockConfig{Base: 5 * time.Minute, Increment: 2500 * time.Millisecond, OnTimeout: TimeoutPolicyForfeit},
+		},
+		"Milliseconds": {
+			json:           `{"Base": 300000, "Increment": 5000, "OnTimeout": "bot", "Bot": "random"}`,
+			expectedConfig: ClockConfig{Base: 5 * time.Minute, Increment: 5 * time.Second, OnTimeout: TimeoutPolicyBot, Bot: "random"},
+		},
+		"No increment": {
+			json:           `{"Base": "1m", "OnTimeout": "random"}`,
+			expectedConfig: ClockConfig{Base: time.Minute, OnTimeout: TimeoutPolicyRandom},
+		},
+		"Error case: not a duration": {
+			json:          `{"Base": "five minutes", "OnTimeout": "random"}`,
+			expectedError: models.InvalidActionError{Message: `The clock's Base isn't a duration like "5m" or "30s": time: invalid duration "five minutes"`},
+		},
+		"Error case: not a number": {
+			json:          `{"Base": "1m", "Increment": true, "OnTimeout": "random"}`,
+			expectedError: models.InvalidActionError{Message: `The clock's Increment must be a duration like "5m", or a number of milliseconds`},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			var config ClockConfig
+			err := json.Unmarshal([]byte(tc.json), &config)
+			assert.So(err, should.Resemble, tc.expectedError)
+			if tc.expectedError != nil {
+				return
+			}
+			assert.So(config, should.Resemble, tc.expectedConfig)
+
+			// The config is written with duration strings, and reads back the same
+			data, err := json.Marshal(config)
+			assert.So(err, should.BeNil)
+			var decoded ClockConfig
+			assert.So(json.Unmarshal(data, &decoded), should.BeNil)
+			assert.So(decoded, should.Resemble, config)
+		})
+	}
+}
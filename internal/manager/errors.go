@@ -0,0 +1,30 @@
+package manager
+
+// NotFoundError is returned when a game or a seat doesn't exist
+type NotFoundError struct {
+	Message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
+// ConflictError is returned when a request can't be carried out in the game's current
+// state, like joining a seat that's already taken
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
+// ForbiddenError is returned when a player tries to do something their token doesn't allow,
+// like making a move for another seat
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,492 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/history"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+type GameStatus string
+
+const (
+	GameStatusWaiting  GameStatus = "waiting"
+	GameStatusPlaying  GameStatus = "playing"
+	GameStatusFinished GameStatus = "finished"
+)
+
+// Game is a game hosted by the manager, along with the log of its moves, its seats, and its
+// subscribers. The mutex guards everything except the ID.
+//
+// Each seat that a person has joined has a secret token, and only that seat's token can be used
+// to make its moves. The creator's token is used to kick players or replace them with bots.
+// Anyone can read the state of the game.
+type Game struct {
+	ID string
+
+	mu           sync.RWMutex
+	log          *history.Log
+	game         *models.Game
+	seats        []Seat
+	bots         map[int]bots.Bot
+	tokens       map[int]string
+	creatorToken string
+	hub          *hub
+	logger       *log.Logger
+	// playingBots is set while the bots are playing, which goes on without holding the lock
+	// while a bot chooses its move
+	playingBots bool
+	// clock is nil if the game isn't timed
+	clock *clock
+
+	// store is nil if the game isn't saved. saveErr is the error from the last save, if it
+	// failed, and the other fields are what's been saved so far.
+	store       Store
+	saveErr     error
+	savedMoves  int
+	savedSeats  []Seat
+	savedTokens map[int]string
+}
+
+// Seat is a place at the table for one player. The game starts once every seat has been joined.
+// Bot is the name of the bot that plays for the seat, or empty if a person is playing. A seat
+// that has forfeited can't win, but its moves are still made so the game can go on.
+type Seat struct {
+	Name      string
+	Joined    bool
+	Bot       string `json:",omitempty"`
+	Forfeited bool   `json:",omitempty"`
+}
+
+// GameState is a copy of everything about a hosted game that clients can see. Clock and
+// Clocks are only set if the game is timed, and Winners is only set once the game is over.
+// SaveError is set if the game's latest changes couldn't be saved. They're saved again with the
+// next change.
+type GameState struct {
+	ID            string
+	Status        GameStatus
+	Seats         []Seat
+	CurrentPlayer int
+	MoveCount     int
+	State         models.GameSnapshot
+	Clock         *ClockConfig `json:",omitempty"`
+	Clocks        []ClockState `json:",omitempty"`
+	Winners       []int        `json:",omitempty"`
+	SaveError     string       `json:",omitempty"`
+}
+
+func (g *Game) status() GameStatus {
+	if g.game.GameOver {
+		return GameStatusFinished
+	}
+	for _, seat := range g.seats {
+		if !seat.Joined {
+			return GameStatusWaiting
+		}
+	}
+	return GameStatusPlaying
+}
+
+func (g *Game) state() GameState {
+	state := GameState{
+		ID:            g.ID,
+		Status:        g.status(),
+		Seats:         append([]Seat{}, g.seats...),
+		CurrentPlayer: g.game.CurrentPlayerKey,
+		MoveCount:     len(g.log.Moves),
+		State:         g.game.Snapshot().Public(),
+	}
+	if g.clock != nil {
+		config := g.clock.config
+		state.Clock = &config
+		state.Clocks = g.clock.states()
+	}
+	if state.Status == GameStatusFinished {
+		state.Winners = g.winners()
+	}
+	if g.saveErr != nil {
+		state.SaveError = g.saveErr.Error()
+	}
+	return state
+}
+
+// winners returns the seats that won the game. The seats that forfeited can't win.
+func (g *Game) winners() []int {
+	contenders := make([]int, 0, len(g.seats))
+	for i, seat := range g.seats {
+		if !seat.Forfeited {
+			contenders = append(contenders, i)
+		}
+	}
+	return g.game.WinnersAmong(contenders)
+}
+
+// publish passes the game's events on to the subscribers. The winners the game announces
+// when it ends are replaced by the seats that could win, so the subscribers agree with the
+// game's state.
+func (g *Game) publish(event models.Event) {
+	if ended, ok := event.(models.GameEnded); ok {
+		ended.Winners = g.winners()
+		event = ended
+	}
+	g.hub.HandleEvent(event)
+}
+
+// broadcastState lets the subscribers know the game has changed. It must be called while
+// holding the write lock, so the states are broadcast in the same order as the changes.
+// It's called after every change, so it keeps the clocks in step with the game, and saves
+// the changes, as well.
+func (g *Game) broadcastState() {
+	g.syncClock()
+	g.save()
+	state := g.state()
+	g.hub.broadcast(Message{Type: MessageTypeState, Game: &state})
+}
+
+// State returns a snapshot of the game
+func (g *Game) State() GameState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.state()
+}
+
+func (g *Game) Summary() GameSummary {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return GameSummary{ID: g.ID, Status: g.status(), Seats: append([]Seat{}, g.seats...)}
+}
+
+// Join claims the seat for a player. The token that's returned is needed to make the seat's moves.
+func (g *Game) Join(seat int, name string) (GameState, string, error) {
+	if name == "" {
+		return GameState{}, "", models.InvalidActionError{Message: "A name is required to join the game"}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.validateSeat(seat); err != nil {
+		return GameState{}, "", err
+	}
+	if g.seats[seat].Joined {
+		return GameState{}, "", ConflictError{Message: fmt.Sprintf("seat #%d has already been taken by %s", seat, g.seats[seat].Name)}
+	}
+
+	token := newToken()
+	g.tokens[seat] = token
+	g.sit(seat, Seat{Name: name, Joined: true})
+
+	return g.state(), token, nil
+}
+
+// Kick empties the seat. The game waits until someone else joins the seat. A forfeited seat
+// can't be kicked.
+func (g *Game) Kick(creatorToken string, seat int) (GameState, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.authorizeCreator(creatorToken); err != nil {
+		return GameState{}, err
+	}
+	if err := g.validateSeat(seat); err != nil {
+		return GameState{}, err
+	}
+	if g.game.GameOver {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("game '%s' is over", g.ID)}
+	}
+	if g.seats[seat].Forfeited {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("seat %d forfeited", seat)}
+	}
+
+	delete(g.tokens, seat)
+	delete(g.bots, seat)
+	g.seats[seat] = Seat{Name: g.seats[seat].Name}
+	g.broadcastState()
+
+	return g.state(), nil
+}
+
+// ReplaceWithBot hands the seat to a bot, which keeps playing the seat's board from where it is. A
+// forfeited seat stays out of the running, so it can't be handed to a bot.
+func (g *Game) ReplaceWithBot(creatorToken string, seat int, botName string) (GameState, error) {
+	bot, err := bots.New(botName)
+	if err != nil {
+		return GameState{}, err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.authorizeCreator(creatorToken); err != nil {
+		return GameState{}, err
+	}
+	if err := g.validateSeat(seat); err != nil {
+		return GameState{}, err
+	}
+	if g.game.GameOver {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("game '%s' is over", g.ID)}
+	}
+	if g.seats[seat].Forfeited {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("seat %d forfeited", seat)}
+	}
+
+	delete(g.tokens, seat)
+	g.bots[seat] = bot
+	g.sit(seat, Seat{Name: fmt.Sprintf("%s bot #%d", botName, seat), Joined: true, Bot: botName})
+
+	return g.state(), nil
+}
+
+// Forfeit takes an abandoned seat out of the running, so the other players can finish the game
+// without it. The seat can't win, and its moves are made at random from then on.
+func (g *Game) Forfeit(creatorToken string, seat int) (GameState, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.authorizeCreator(creatorToken); err != nil {
+		return GameState{}, err
+	}
+	if err := g.validateSeat(seat); err != nil {
+		return GameState{}, err
+	}
+	if g.game.GameOver {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("game '%s' is over", g.ID)}
+	}
+
+	g.forfeit(seat)
+	return g.state(), nil
+}
+
+// Resign lets a player leave the game. The token is the player's seat token. If botName is
+// empty, the seat forfeits. Otherwise the bot takes over the seat and plays on from the
+// player's board, keeping its score and wall.
+func (g *Game) Resign(token string, botName string) (GameState, error) {
+	var bot bots.Bot
+	if botName != "" {
+		var err error
+		if bot, err = bots.New(botName); err != nil {
+			return GameState{}, err
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.game.GameOver {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("game '%s' is over", g.ID)}
+	}
+	seat, ok := g.seatForToken(token)
+	if !ok {
+		return GameState{}, ForbiddenError{Message: fmt.Sprintf("only a player in game '%s' can resign", g.ID)}
+	}
+
+	if bot == nil {
+		g.forfeit(seat)
+	} else {
+		g.handToBot(seat, botName, bot)
+	}
+	return g.state(), nil
+}
+
+// sit puts a player in the seat, and lets the bots play if that means the game can go on. It
+// must be called while holding the write lock.
+func (g *Game) sit(seat int, s Seat) {
+	g.seats[seat] = s
+	g.rename(seat, s.Name)
+
+	g.broadcastState()
+	g.playBots()
+}
+
+// rename changes the seat's player's name. It's changed in the log as well, so rebuilding the
+// game from the log uses the name of the player who has the seat now.
+func (g *Game) rename(seat int, name string) {
+	g.log.Players[seat] = name
+	player := g.game.Players[seat]
+	player.Name = name
+	g.game.Players[seat] = player
+}
+
+func (g *Game) validateSeat(seat int) error {
+	if seat < 0 || seat >= len(g.seats) {
+		return NotFoundError{Message: fmt.Sprintf("game '%s' has no seat #%d", g.ID, seat)}
+	}
+	return nil
+}
+
+func (g *Game) authorizeCreator(token string) error {
+	if !tokensMatch(g.creatorToken, token) {
+		return ForbiddenError{Message: fmt.Sprintf("only the creator of game '%s' can do that", g.ID)}
+	}
+	return nil
+}
+
+// seatForToken returns the seat that the token belongs to
+func (g *Game) seatForToken(token string) (int, bool) {
+	for seat, seatToken := range g.tokens {
+		if tokensMatch(seatToken, token) {
+			return seat, true
+		}
+	}
+	return 0, false
+}
+
+func (g *Game) authorizeSeat(token string, seat int) error {
+	if !tokensMatch(g.tokens[seat], token) {
+		return ForbiddenError{Message: fmt.Sprintf("it's %s's turn, and only they can move", g.seats[seat].Name)}
+	}
+	return nil
+}
+
+// LegalMoves returns the moves the current player is allowed to make. There aren't any until
+// every seat has been joined.
+func (g *Game) LegalMoves() (int, []models.Move) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.status() != GameStatusPlaying {
+		return g.game.CurrentPlayerKey, []models.Move{}
+	}
+	return g.game.CurrentPlayerKey, g.game.LegalMoves()
+}
+
+// MakeMove validates the move and makes it for the current player. The token must be the
+// current player's seat token.
+func (g *Game) MakeMove(token string, move models.Move) (GameState, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if status := g.status(); status != GameStatusPlaying {
+		return GameState{}, ConflictError{Message: fmt.Sprintf("game '%s' is %s", g.ID, status)}
+	}
+	seat := g.game.CurrentPlayerKey
+	if err := g.authorizeSeat(token, seat); err != nil {
+		return GameState{}, err
+	}
+	if err := g.game.ValidateMove(move); err != nil {
+		return GameState{}, err
+	}
+	if !g.chargeMove(seat) {
+		// The move came in before the timer went off, but it's too late all the same
+		name := g.seats[seat].Name
+		g.timeOut(seat)
+		return GameState{}, ConflictError{Message: fmt.Sprintf("%s ran out of time", name)}
+	}
+	if err := g.log.Record(g.game, move); err != nil {
+		g.syncClock()
+		return GameState{}, err
+	}
+	g.broadcastState()
+	g.playBots()
+
+	return g.state(), nil
+}
+
+// History returns the players and moves of the game. The seed stays on the server, since it
+// tells which tiles will be drawn.
+func (g *Game) History() (players []string, moves []models.Move) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return append([]string{}, g.log.Players...), append([]models.Move{}, g.log.Moves...)
+}
+
+// Subscribe starts receiving the game's events, and its state after every change. The current
+// state is returned along with the subscription, so nothing is missed in between.
+func (g *Game) Subscribe() (GameState, *Subscription) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.state(), g.hub.subscribe()
+}
+
+// start plays the bots' turns and starts the clock of a game that has just been added to the
+// manager. It's called without holding the manager's lock, because the bots can take a while.
+func (g *Game) start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.playBots()
+	g.syncClock()
+}
+
+// playBots makes the moves for the bots until it's a person's turn, or the game is over. It
+// must be called while holding the write lock, but the lock is let go while a bot chooses its
+// move, since that can take a while. The move is only made if the game is still at the turn the
+// bot was asked about. Only one call plays the bots at a time; the others leave it to that one.
+func (g *Game) playBots() {
+	if g.playingBots {
+		return
+	}
+	g.playingBots = true
+	defer func() { g.playingBots = false }()
+
+	for g.status() == GameStatusPlaying {
+		seat := g.game.CurrentPlayerKey
+		bot, ok := g.bots[seat]
+		if !ok {
+			return
+		}
+		moveCount := len(g.log.Moves)
+		position := models.RestoreGame(g.game.Snapshot())
+
+		g.mu.Unlock()
+		move, err := bot.ChooseMove(position)
+		g.mu.Lock()
+
+		if _, ok := g.bots[seat]; !ok || len(g.log.Moves) != moveCount || g.status() != GameStatusPlaying {
+			// The game moved on while the bot was choosing, so its move is out of date
+			continue
+		}
+		if err == nil {
+			err = g.log.Record(g.game, move)
+		}
+		if err != nil {
+			if g.seats[seat].Forfeited {
+				// The seat's moves are already random, so the game itself is broken
+				g.logger.Printf("game %s: no move can be made for seat #%d, so the game can't go on: %s", g.ID, seat, err)
+				return
+			}
+			// This would be a bug in the bot. The seat forfeits, so the players can see that
+			// something went wrong, and its moves are made at random so the game can go on.
+			g.logger.Printf("game %s: the bot in seat #%d failed to move, so the seat forfeits: %s", g.ID, seat, err)
+			g.forfeit(seat)
+			continue
+		}
+		g.broadcastState()
+	}
+}
+
+// playRandomMove makes a random legal move for the current player. It must be called while
+// holding the write lock.
+func (g *Game) playRandomMove() error {
+	move, err := bots.NewRandomBot(time.Now().UnixNano()).ChooseMove(g.game)
+	if err != nil {
+		return err
+	}
+	return g.log.Record(g.game, move)
+}
+
+// handToBot has the bot take over the seat, and play on from its board. It must be called
+// while holding the write lock.
+func (g *Game) handToBot(seat int, botName string, bot bots.Bot) {
+	delete(g.tokens, seat)
+	g.bots[seat] = bot
+	g.sit(seat, Seat{Name: g.seats[seat].Name, Joined: true, Bot: botName})
+}
+
+// forfeit takes the seat out of the running. Its moves are made at random from then on, so
+// the game can go on without it. It must be called while holding the write lock.
+func (g *Game) forfeit(seat int) {
+	delete(g.tokens, seat)
+	g.bots[seat] = bots.NewRandomBot(time.Now().UnixNano())
+	g.seats[seat].Forfeited = true
+	g.broadcastState()
+	g.playBots()
+}
@@ -0,0 +1,112 @@
+package manager
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// MessageTypeState is the type of the message that carries the full state of a game. It's
+// sent after every change to the game. All the other messages carry a game event, and their
+// type is the event's type.
+const MessageTypeState = "State"
+
+// Message is passed to the subscribers of a game
+type Message struct {
+	Type  string
+	Event models.Event `json:",omitempty"`
+	Game  *GameState   `json:",omitempty"`
+}
+
+// UnmarshalJSON decodes a message, including the event it carries. It lets clients read the
+// messages that a server sends.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type  string
+		Event json.RawMessage
+		Game  *GameState
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	m.Type, m.Game, m.Event = raw.Type, raw.Game, nil
+	if len(raw.Event) > 0 {
+		event, err := models.DecodeEvent(models.EventType(raw.Type), raw.Event)
+		if err != nil {
+			return err
+		}
+		m.Event = event
+	}
+
+	return nil
+}
+
+// subscriptionBufferSize is how many messages can be waiting for a slow subscriber before
+// the subscription is cancelled
+const subscriptionBufferSize = 64
+
+// Subscription receives the messages of a game until it's cancelled. Messages is closed when
+// the subscription is cancelled, including when the subscriber couldn't keep up.
+type Subscription struct {
+	Messages <-chan Message
+	messages chan Message
+	hub      *hub
+}
+
+func (s *Subscription) Cancel() {
+	s.hub.unsubscribe(s)
+}
+
+// hub passes messages to every subscriber of a game
+type hub struct {
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func newHub() *hub {
+	return &hub{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+func (h *hub) subscribe() *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	messages := make(chan Message, subscriptionBufferSize)
+	sub := &Subscription{Messages: messages, messages: messages, hub: h}
+	h.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+func (h *hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscriptions[sub]; ok {
+		delete(h.subscriptions, sub)
+		close(sub.messages)
+	}
+}
+
+// broadcast never blocks. A subscriber that can't keep up is dropped.
+func (h *hub) broadcast(message Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscriptions {
+		select {
+		case sub.messages <- message:
+		default:
+			delete(h.subscriptions, sub)
+			close(sub.messages)
+		}
+	}
+}
+
+// HandleEvent lets the hub observe a game, and broadcast every event it publishes
+func (h *hub) HandleEvent(event models.Event) {
+	h.broadcast(Message{Type: string(event.Type()), Event: event})
+}
@@ -0,0 +1,265 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Table is an open table in the lobby, where players gather before a game starts. The host
+// opens the table and takes the first seat. Other players join, or the host fills seats with
+// bots, and the game starts when the table is full or when the host starts it. Each player
+// gets the token for their seat when they sit down, and the host's token is also the game's
+// creator token.
+type Table struct {
+	ID         string
+	Host       string
+	NumPlayers int
+	Config     models.GameConfig
+	// Clock is nil if the game won't be timed
+	Clock *ClockConfig `json:",omitempty"`
+	Seats []Seat
+	// GameID is set once the game has started
+	GameID string `json:",omitempty"`
+
+	// tokens holds the token of each seat, or an empty string for the bots
+	tokens []string
+}
+
+// copy returns a copy of the table without the tokens
+func (t *Table) copy() Table {
+	result := *t
+	result.Seats = append([]Seat{}, t.Seats...)
+	result.tokens = nil
+	return result
+}
+
+// OpenTable opens a table for numPlayers players, with the host in the first seat. The game
+// isn't timed if clock is nil. The host's token is returned.
+func (m *Manager) OpenTable(host string, numPlayers int, config models.GameConfig, clock *ClockConfig) (Table, string, error) {
+	if host == "" {
+		return Table{}, "", models.InvalidActionError{Message: "A name is required to open a table"}
+	}
+	if err := config.Validate(); err != nil {
+		return Table{}, "", err
+	}
+	if err := config.ValidateNumberOfPlayers(numPlayers); err != nil {
+		return Table{}, "", err
+	}
+	if clock != nil {
+		if err := clock.Validate(); err != nil {
+			return Table{}, "", err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextTableID++
+	token := newToken()
+	table := &Table{
+		ID:         strconv.Itoa(m.nextTableID),
+		Host:       host,
+		NumPlayers: numPlayers,
+		Config:     config,
+		Clock:      clock,
+		Seats:      []Seat{{Name: host, Joined: true}},
+		tokens:     []string{token},
+	}
+	m.tables[table.ID] = table
+
+	return table.copy(), token, nil
+}
+
+// JoinTable takes the next free seat at the table, and returns the seat's token. The game
+// starts if the table is full.
+func (m *Manager) JoinTable(id string, name string) (Table, string, error) {
+	if name == "" {
+		return Table{}, "", models.InvalidActionError{Message: "A name is required to join a table"}
+	}
+
+	var started *Game
+	defer func() { startGame(started) }()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, err := m.openTable(id)
+	if err != nil {
+		return Table{}, "", err
+	}
+	for _, seat := range table.Seats {
+		if seat.Name == name {
+			return Table{}, "", ConflictError{Message: fmt.Sprintf("%s is already sitting at table '%s'", name, id)}
+		}
+	}
+
+	token := newToken()
+	result, started, err := m.sit(table, Seat{Name: name, Joined: true}, token)
+	if err != nil {
+		return Table{}, "", err
+	}
+	return result, token, nil
+}
+
+// AddBot fills the next free seat at the table with a bot. Only the host can add bots, so the
+// token must be the host's token. The game starts if the table is full.
+func (m *Manager) AddBot(id string, token string, botName string) (Table, error) {
+	if _, err := bots.New(botName); err != nil {
+		return Table{}, err
+	}
+
+	var started *Game
+	defer func() { startGame(started) }()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, err := m.openTable(id)
+	if err != nil {
+		return Table{}, err
+	}
+	if !tokensMatch(table.tokens[0], token) {
+		return Table{}, ForbiddenError{Message: fmt.Sprintf("only %s can add bots to table '%s'", table.Host, id)}
+	}
+
+	result, started, err := m.sit(table, Seat{Name: fmt.Sprintf("%s bot #%d", botName, len(table.Seats)), Joined: true, Bot: botName}, "")
+	return result, err
+}
+
+// StartTable starts the game with the players who are sitting at the table. Only the host
+// can start the game before the table is full, so the token must be the host's token.
+func (m *Manager) StartTable(id string, token string) (Table, error) {
+	var started *Game
+	defer func() { startGame(started) }()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, err := m.openTable(id)
+	if err != nil {
+		return Table{}, err
+	}
+	if !tokensMatch(table.tokens[0], token) {
+		return Table{}, ForbiddenError{Message: fmt.Sprintf("only %s can start the game at table '%s'", table.Host, id)}
+	}
+
+	result, started, err := m.start(table)
+	return result, err
+}
+
+// GetTable returns the table with the ID, whether or not its game has started
+func (m *Manager) GetTable(id string) (Table, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	table, ok := m.tables[id]
+	if !ok {
+		return Table{}, NotFoundError{Message: fmt.Sprintf("table '%s' was not found", id)}
+	}
+	return table.copy(), nil
+}
+
+// openTable returns the table if its game hasn't started yet. It must be called while holding
+// the manager's lock.
+func (m *Manager) openTable(id string) (*Table, error) {
+	table, ok := m.tables[id]
+	if !ok {
+		return nil, NotFoundError{Message: fmt.Sprintf("table '%s' was not found", id)}
+	}
+	if table.GameID != "" {
+		return nil, ConflictError{Message: fmt.Sprintf("the game at table '%s' has already started", id)}
+	}
+	return table, nil
+}
+
+// sit puts the seat at the table, and creates the game if that filled the table. It must be
+// called while holding the manager's write lock. The game, if there is one, must be started
+// with startGame once the lock has been released.
+func (m *Manager) sit(table *Table, seat Seat, token string) (Table, *Game, error) {
+	if len(table.Seats) >= table.NumPlayers {
+		return Table{}, nil, ConflictError{Message: fmt.Sprintf("table '%s' is full", table.ID)}
+	}
+	table.Seats = append(table.Seats, seat)
+	table.tokens = append(table.tokens, token)
+
+	if len(table.Seats) == table.NumPlayers {
+		return m.start(table)
+	}
+	return table.copy(), nil, nil
+}
+
+// start creates the game for the players at the table. It must be called while holding the
+// manager's write lock, and the game must be started with startGame once the lock has been
+// released.
+func (m *Manager) start(table *Table) (Table, *Game, error) {
+	if err := table.Config.ValidateNumberOfPlayers(len(table.Seats)); err != nil {
+		return Table{}, nil, err
+	}
+
+	tokens := make(map[int]string)
+	for i, token := range table.tokens {
+		if token != "" {
+			tokens[i] = token
+		}
+	}
+
+	game, err := m.addGame(table.Config, table.Seats, nil, table.Clock, table.tokens[0], tokens)
+	if err != nil {
+		return Table{}, nil, err
+	}
+	table.GameID = game.ID
+
+	return table.copy(), game, nil
+}
+
+// startGame starts a table's game if it has been created. It's deferred before the manager's
+// lock is taken, so that it runs after the lock has been released.
+func startGame(game *Game) {
+	if game != nil {
+		game.start()
+	}
+}
+
+// Lobby lists the open tables, and the games on the server by status
+type Lobby struct {
+	Tables   []Table
+	Waiting  []GameSummary
+	Running  []GameSummary
+	Finished []GameSummary
+}
+
+func (m *Manager) Lobby() Lobby {
+	lobby := Lobby{
+		Tables:   make([]Table, 0),
+		Waiting:  make([]GameSummary, 0),
+		Running:  make([]GameSummary, 0),
+		Finished: make([]GameSummary, 0),
+	}
+
+	m.mu.RLock()
+	for _, table := range m.tables {
+		if table.GameID == "" {
+			lobby.Tables = append(lobby.Tables, table.copy())
+		}
+	}
+	m.mu.RUnlock()
+	sort.Slice(lobby.Tables, func(i, j int) bool {
+		a, _ := strconv.Atoi(lobby.Tables[i].ID)
+		b, _ := strconv.Atoi(lobby.Tables[j].ID)
+		return a < b
+	})
+
+	for _, summary := range m.List() {
+		switch summary.Status {
+		case GameStatusWaiting:
+			lobby.Waiting = append(lobby.Waiting, summary)
+		case GameStatusPlaying:
+			lobby.Running = append(lobby.Running, summary)
+		case GameStatusFinished:
+			lobby.Finished = append(lobby.Finished, summary)
+		}
+	}
+
+	return lobby
+}
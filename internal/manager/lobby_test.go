@@ -0,0 +1,94 @@
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func TestManager_Tables(t *testing.T) {
+	assert := assertions.New(t)
+	m := NewManager()
+
+	_, _, err := m.OpenTable("alice", 5, models.DefaultGameConfig, nil)
+	assert.So(errors.As(err, &models.InvalidActionError{}), should.BeTrue)
+	_, _, err = m.OpenTable("alice", 2, models.GameConfig{TilesPerColor: 1000000, TilesPerFactory: 4}, nil)
+	assert.So(errors.As(err, &models.InvalidActionError{}), should.BeTrue)
+
+	table, aliceToken, err := m.OpenTable("alice", 4, models.DefaultGameConfig, nil)
+	assert.So(err, should.BeNil)
+	assert.So(table.Seats, should.Resemble, []Seat{{Name: "alice", Joined: true}})
+
+	// The host can't start the game alone
+	_, err = m.StartTable(table.ID, aliceToken)
+	assert.So(err, should.NotBeNil)
+
+	table, bobToken, err := m.JoinTable(table.ID, "bob")
+	assert.So(err, should.BeNil)
+	_, _, err = m.JoinTable(table.ID, "bob")
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+	_, err = m.AddBot(table.ID, aliceToken, "grandmaster")
+	assert.So(errors.As(err, &models.InvalidActionError{}), should.BeTrue)
+	_, err = m.AddBot(table.ID, bobToken, "greedy")
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	table, err = m.AddBot(table.ID, aliceToken, "greedy")
+	assert.So(err, should.BeNil)
+	assert.So(len(table.Seats), should.Equal, 3)
+
+	assert.So(len(m.Lobby().Tables), should.Equal, 1)
+
+	// Only the host can start the game before the table is full
+	_, err = m.StartTable(table.ID, bobToken)
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	table, err = m.StartTable(table.ID, aliceToken)
+	assert.So(err, should.BeNil)
+	assert.So(table.GameID, should.NotBeEmpty)
+
+	_, _, err = m.JoinTable(table.ID, "carol")
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+
+	game, err := m.Get(table.GameID)
+	assert.So(err, should.BeNil)
+	state := game.State()
+	assert.So(state.Status, should.Equal, GameStatusPlaying)
+
+	// The tokens from the table carry over to the game
+	_, moves := game.LegalMoves()
+	_, err = game.MakeMove(bobToken, moves[0])
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	_, err = game.MakeMove(aliceToken, moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(len(state.State.Players), should.Equal, 3)
+	assert.So(state.Seats[2].Bot, should.Equal, "greedy")
+
+	lobby := m.Lobby()
+	assert.So(lobby.Tables, should.BeEmpty)
+	assert.So(len(lobby.Running), should.Equal, 1)
+}
+
+func TestManager_TableStartsWhenFull(t *testing.T) {
+	assert := assertions.New(t)
+	m := NewManager()
+
+	table, aliceToken, _ := m.OpenTable("alice", 2, models.DefaultGameConfig, nil)
+	table, err := m.AddBot(table.ID, aliceToken, "random")
+	assert.So(err, should.BeNil)
+	assert.So(table.GameID, should.NotBeEmpty)
+
+	game, _ := m.Get(table.GameID)
+
+	// The bot plays its turns right after each of alice's moves, so it's always alice's turn
+	for game.State().Status == GameStatusPlaying {
+		currentPlayer, moves := game.LegalMoves()
+		assert.So(currentPlayer, should.Equal, 0)
+		_, err := game.MakeMove(aliceToken, moves[0])
+		assert.So(err, should.BeNil)
+	}
+
+	assert.So(game.State().Status, should.Equal, GameStatusFinished)
+	assert.So(len(m.Lobby().Finished), should.Equal, 1)
+}
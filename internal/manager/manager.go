@@ -0,0 +1,206 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/bots"
+	"github.com/aaron-zeisler/azul/internal/history"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Manager holds the games hosted by a server. It's safe to use from many goroutines at once:
+// changes to a game are made one at a time, while any number of readers can take snapshots
+// of it concurrently.
+//
+// If the manager has a store, every change to a game is saved to it, and Restore brings the
+// games back after a restart. The tables in the lobby aren't saved.
+type Manager struct {
+	mu          sync.RWMutex
+	games       map[string]*Game
+	nextID      int
+	tables      map[string]*Table
+	nextTableID int
+	store       Store
+	logger      *log.Logger
+}
+
+type NewManagerOption func(m *Manager)
+
+// WithStore saves the games to the store
+func WithStore(store Store) NewManagerOption {
+	return func(m *Manager) {
+		m.store = store
+	}
+}
+
+// WithLogger logs the errors that come up while the games are played on their own, for example
+// when a bot fails or a game can't be saved. They're logged to stderr by default.
+func WithLogger(logger *log.Logger) NewManagerOption {
+	return func(m *Manager) {
+		m.logger = logger
+	}
+}
+
+func NewManager(opts ...NewManagerOption) *Manager {
+	m := &Manager{
+		games:  make(map[string]*Game),
+		tables: make(map[string]*Table),
+		logger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Create sets up a new game with empty seats. A random seed is used if seed is nil, and the
+// game isn't timed if clock is nil. The token that's returned lets the creator manage the
+// game's seats.
+func (m *Manager) Create(config models.GameConfig, numPlayers int, seed *int64, clock *ClockConfig) (*Game, string, error) {
+	if err := config.Validate(); err != nil {
+		return nil, "", err
+	}
+	if err := config.ValidateNumberOfPlayers(numPlayers); err != nil {
+		return nil, "", err
+	}
+	if clock != nil {
+		if err := clock.Validate(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	seats := make([]Seat, numPlayers)
+	for i := range seats {
+		seats[i].Name = fmt.Sprintf("Seat %d", i)
+	}
+
+	m.mu.Lock()
+	creatorToken := newToken()
+	game, err := m.addGame(config, seats, seed, clock, creatorToken, map[int]string{})
+	m.mu.Unlock()
+	if err != nil {
+		return nil, "", err
+	}
+
+	game.start()
+	return game, creatorToken, nil
+}
+
+// addGame creates a game for the seats, where tokens holds the tokens of the seats that have
+// been joined by people. It must be called while holding the manager's write lock, and the
+// game must be started once the lock has been released.
+func (m *Manager) addGame(config models.GameConfig, seats []Seat, seed *int64, clock *ClockConfig, creatorToken string, tokens map[int]string) (*Game, error) {
+	gameSeed := time.Now().UnixNano()
+	if seed != nil {
+		gameSeed = *seed
+	}
+
+	game, err := newGame(strconv.Itoa(m.nextID+1), config, seats, gameSeed, clock, creatorToken, tokens)
+	if err != nil {
+		return nil, err
+	}
+	m.nextID++
+	game.store = m.store
+	game.logger = m.logger
+	game.saveCreated()
+	m.games[game.ID] = game
+
+	return game, nil
+}
+
+// newGame sets up a game, without starting the bots or the clocks
+func newGame(id string, config models.GameConfig, seats []Seat, seed int64, clock *ClockConfig, creatorToken string, tokens map[int]string) (*Game, error) {
+	gameBots, err := seatBots(seats)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(seats))
+	for i, seat := range seats {
+		names[i] = seat.Name
+	}
+	if tokens == nil {
+		tokens = make(map[int]string)
+	}
+
+	gameLog := history.NewLog(config, seed, names)
+	game := &Game{
+		ID:           id,
+		log:          gameLog,
+		seats:        append([]Seat{}, seats...),
+		bots:         gameBots,
+		tokens:       tokens,
+		creatorToken: creatorToken,
+		hub:          newHub(),
+	}
+	game.game = gameLog.NewGame(models.WithObserver(models.ObserverFunc(game.publish)))
+	if clock != nil {
+		game.clock = newClock(*clock, len(seats))
+	}
+	return game, nil
+}
+
+// seatBots sets up the bots that play for the seats. The seats that have forfeited are played
+// at random.
+func seatBots(seats []Seat) (map[int]bots.Bot, error) {
+	gameBots := make(map[int]bots.Bot)
+	for i, seat := range seats {
+		switch {
+		case seat.Forfeited:
+			gameBots[i] = bots.NewRandomBot(time.Now().UnixNano())
+		case seat.Bot != "":
+			bot, err := bots.New(seat.Bot)
+			if err != nil {
+				return nil, err
+			}
+			gameBots[i] = bot
+		}
+	}
+	return gameBots, nil
+}
+
+// Get returns the game with the ID
+func (m *Manager) Get(id string) (*Game, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	game, ok := m.games[id]
+	if !ok {
+		return nil, NotFoundError{Message: fmt.Sprintf("game '%s' was not found", id)}
+	}
+	return game, nil
+}
+
+// GameSummary is a short description of a game, used to list the games on the server
+type GameSummary struct {
+	ID     string
+	Status GameStatus
+	Seats  []Seat
+}
+
+// List returns a summary of every game, ordered by ID
+func (m *Manager) List() []GameSummary {
+	m.mu.RLock()
+	games := make([]*Game, 0, len(m.games))
+	for _, game := range m.games {
+		games = append(games, game)
+	}
+	m.mu.RUnlock()
+
+	summaries := make([]GameSummary, 0, len(games))
+	for _, game := range games {
+		summaries = append(summaries, game.Summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, _ := strconv.Atoi(summaries[i].ID)
+		b, _ := strconv.Atoi(summaries[j].ID)
+		return a < b
+	})
+
+	return summaries
+}
@@ -0,0 +1,340 @@
+package manager
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// createJoinedGame creates a game, and has each player join a seat. It returns the game, the
+// creator's token, and each seat's token.
+func createJoinedGame(t *testing.T, m *Manager, players []string) (*Game, string, []string) {
+	seed := int64(len(players))
+	game, creatorToken, err := m.Create(models.DefaultGameConfig, len(players), &seed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := make([]string, len(players))
+	for i, name := range players {
+		if _, tokens[i], err = game.Join(i, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return game, creatorToken, tokens
+}
+
+func TestManager_Create(t *testing.T) {
+	assert := assertions.New(t)
+	m := NewManager()
+
+	_, _, err := m.Create(models.DefaultGameConfig, 5, nil, nil)
+	assert.So(err, should.NotBeNil)
+
+	game, creatorToken, err := m.Create(models.DefaultGameConfig, 3, nil, nil)
+	assert.So(err, should.BeNil)
+	assert.So(creatorToken, should.NotBeEmpty)
+	assert.So(game.State().Status, should.Equal, GameStatusWaiting)
+
+	found, err := m.Get(game.ID)
+	assert.So(err, should.BeNil)
+	assert.So(found, should.Equal, game)
+
+	_, err = m.Get("nope")
+	assert.So(errors.As(err, &NotFoundError{}), should.BeTrue)
+}
+
+func TestGame_Join(t *testing.T) {
+	assert := assertions.New(t)
+	m := NewManager()
+	game, _, _ := m.Create(models.DefaultGameConfig, 2, nil, nil)
+
+	_, err := game.MakeMove("", models.Move{})
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+
+	state, aliceToken, err := game.Join(0, "alice")
+	assert.So(err, should.BeNil)
+	assert.So(aliceToken, should.NotBeEmpty)
+	assert.So(state.Status, should.Equal, GameStatusWaiting)
+
+	_, _, err = game.Join(0, "eve")
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+	_, _, err = game.Join(2, "eve")
+	assert.So(errors.As(err, &NotFoundError{}), should.BeTrue)
+	_, _, err = game.Join(1, "")
+	assert.So(errors.As(err, &models.InvalidActionError{}), should.BeTrue)
+
+	state, bobToken, err := game.Join(1, "bob")
+	assert.So(err, should.BeNil)
+	assert.So(bobToken, should.NotEqual, aliceToken)
+	assert.So(state.Status, should.Equal, GameStatusPlaying)
+	assert.So(state.State.Players[1].Name, should.Equal, "bob")
+}
+
+func TestGame_Authorization(t *testing.T) {
+	assert := assertions.New(t)
+	m := NewManager()
+	game, creatorToken, tokens := createJoinedGame(t, m, []string{"alice", "bob", "carol"})
+	_, moves := game.LegalMoves()
+
+	// Only the current player can move, and only with their own token
+	for _, token := range []string{"", "guess", tokens[1], creatorToken} {
+		_, err := game.MakeMove(token, moves[0])
+		assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	}
+	state, err := game.MakeMove(tokens[0], moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(state.CurrentPlayer, should.Equal, 1)
+
+	// Only the creator can manage the seats
+	_, err = game.Kick(tokens[0], 1)
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	_, err = game.ReplaceWithBot(tokens[1], 1, "greedy")
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+
+	// After bob is kicked, the game waits for someone else to take the seat
+	state, err = game.Kick(creatorToken, 1)
+	assert.So(err, should.BeNil)
+	assert.So(state.Status, should.Equal, GameStatusWaiting)
+	_, err = game.MakeMove(tokens[1], moves[0])
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+
+	state, daveToken, err := game.Join(1, "dave")
+	assert.So(err, should.BeNil)
+	assert.So(state.Status, should.Equal, GameStatusPlaying)
+	_, moves = game.LegalMoves()
+	_, err = game.MakeMove(tokens[1], moves[0])
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	_, err = game.MakeMove(daveToken, moves[0])
+	assert.So(err, should.BeNil)
+
+	// Carol is replaced by a bot, which plays carol's turn right away
+	state, err = game.ReplaceWithBot(creatorToken, 2, "greedy")
+	assert.So(err, should.BeNil)
+	assert.So(state.Seats[2].Bot, should.Equal, "greedy")
+	assert.So(state.CurrentPlayer, should.NotEqual, 2)
+	assert.So(state.MoveCount, should.Equal, 3)
+}
+
+func TestGame_Resign(t *testing.T) {
+	assert := assertions.New(t)
+	m := NewManager()
+	game, creatorToken, tokens := createJoinedGame(t, m, []string{"alice", "bob", "carol"})
+	_, moves := game.LegalMoves()
+	_, err := game.MakeMove(tokens[0], moves[0])
+	assert.So(err, should.BeNil)
+	boardBefore := game.State().State.Players[0].Board
+
+	_, err = game.Resign("guess", "")
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	_, err = game.Resign(tokens[0], "nobody")
+	assert.So(errors.As(err, &models.InvalidActionError{}), should.BeTrue)
+
+	// A bot takes over alice's board, and plays on from there
+	state, err := game.Resign(tokens[0], "greedy")
+	assert.So(err, should.BeNil)
+	assert.So(state.Seats[0], should.Resemble, Seat{Name: "alice", Joined: true, Bot: "greedy"})
+	assert.So(state.State.Players[0].Board, should.Resemble, boardBefore)
+	_, err = game.Resign(tokens[0], "")
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+
+	// bob forfeits in the middle of their turn, so a move is made for the seat straight away
+	state, err = game.Resign(tokens[1], "")
+	assert.So(err, should.BeNil)
+	assert.So(state.Seats[1].Forfeited, should.BeTrue)
+	assert.So(state.CurrentPlayer, should.Equal, 2)
+
+	// A forfeited seat stays out of the running, so it can't be kicked or handed to a bot
+	_, err = game.Kick(creatorToken, 1)
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+	_, err = game.ReplaceWithBot(creatorToken, 1, "greedy")
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+	assert.So(game.State().Seats[1].Forfeited, should.BeTrue)
+
+	// Only the creator can forfeit an abandoned seat, and then the bots play the game out
+	_, err = game.Forfeit(tokens[2], 2)
+	assert.So(errors.As(err, &ForbiddenError{}), should.BeTrue)
+	state, err = game.Forfeit(creatorToken, 2)
+	assert.So(err, should.BeNil)
+	assert.So(state.Status, should.Equal, GameStatusFinished)
+
+	// Only alice's bot is still in the running
+	assert.So(state.Winners, should.Resemble, []int{0})
+	_, err = game.Resign(tokens[2], "")
+	assert.So(errors.As(err, &ConflictError{}), should.BeTrue)
+}
+
+// failingBot never manages to choose a move
+type failingBot struct{}
+
+func (b failingBot) ChooseMove(game *models.Game) (models.Move, error) {
+	return models.Move{}, errors.New("out of ideas")
+}
+
+func TestGame_BotFails(t *testing.T) {
+	assert := assertions.New(t)
+	game, _, tokens := createJoinedGame(t, NewManager(), []string{"alice", "bob"})
+	game.mu.Lock()
+	game.bots[1] = failingBot{}
+	game.mu.Unlock()
+
+	// The bot's seat forfeits, and a random move is made for it, so alice can play on
+	_, moves := game.LegalMoves()
+	state, err := game.MakeMove(tokens[0], moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(state.Seats[1].Forfeited, should.BeTrue)
+	assert.So(state.MoveCount, should.Equal, 2)
+	assert.So(state.CurrentPlayer, should.Equal, 0)
+}
+
+// slowBot waits to be told which move to make
+type slowBot struct {
+	choosing chan struct{}
+	moves    chan models.Move
+}
+
+func (b slowBot) ChooseMove(game *models.Game) (models.Move, error) {
+	b.choosing <- struct{}{}
+	return <-b.moves, nil
+}
+
+func TestGame_BotChoosesWithoutTheLock(t *testing.T) {
+	assert := assertions.New(t)
+	game, creatorToken, tokens := createJoinedGame(t, NewManager(), []string{"alice", "bob"})
+	bot := slowBot{choosing: make(chan struct{}), moves: make(chan models.Move)}
+	game.mu.Lock()
+	game.bots[1] = bot
+	game.mu.Unlock()
+
+	_, moves := game.LegalMoves()
+	made := make(chan GameState)
+	go func() {
+		state, err := game.MakeMove(tokens[0], moves[0])
+		assert.So(err, should.BeNil)
+		made <- state
+	}()
+
+	// The game can be read while the bot is choosing
+	<-bot.choosing
+	assert.So(game.State().MoveCount, should.Equal, 1)
+	_, moves = game.LegalMoves()
+	bot.moves <- moves[0]
+	state := <-made
+	assert.So(state.MoveCount, should.Equal, 2)
+	assert.So(state.CurrentPlayer, should.Equal, 0)
+
+	// bob's bot is kicked while it's choosing, so its move is thrown away
+	_, moves = game.LegalMoves()
+	go func() {
+		state, err := game.MakeMove(tokens[0], moves[0])
+		assert.So(err, should.BeNil)
+		made <- state
+	}()
+	<-bot.choosing
+	_, moves = game.LegalMoves()
+	_, err := game.Kick(creatorToken, 1)
+	assert.So(err, should.BeNil)
+	bot.moves <- moves[0]
+	state = <-made
+	assert.So(state.MoveCount, should.Equal, 3)
+	assert.So(state.Status, should.Equal, GameStatusWaiting)
+}
+
+// TestManager_ConcurrentLoad plays many games at once, with several goroutines racing to
+// make moves in each game while others read it. Run it with -race.
+func TestManager_ConcurrentLoad(t *testing.T) {
+	const numGames = 12
+	const movers = 4
+	const readers = 2
+
+	m := NewManager()
+	games := make([]*Game, numGames)
+	tokens := make([][]string, numGames)
+	for i := range games {
+		games[i], _, tokens[i] = createJoinedGame(t, m, []string{"alice", "bob", "carol", "dave"}[:2+i%3])
+	}
+
+	var wg sync.WaitGroup
+	for i, game := range games {
+		// Subscribe before anyone moves, so the subscriber can't miss the end of the game
+		_, sub := game.Subscribe()
+		wg.Add(1)
+		go func(sub *Subscription) {
+			defer wg.Done()
+			defer sub.Cancel()
+			for message := range sub.Messages {
+				if message.Type == string(models.EventGameEnded) {
+					return
+				}
+			}
+		}(sub)
+
+		for j := 0; j < movers; j++ {
+			wg.Add(1)
+			go func(game *Game, tokens []string, seed int64) {
+				defer wg.Done()
+				r := rand.New(rand.NewSource(seed))
+				for game.State().Status == GameStatusPlaying {
+					currentPlayer, moves := game.LegalMoves()
+					if len(moves) == 0 {
+						continue
+					}
+					// Another goroutine may have moved first, so the move can be rejected
+					_, err := game.MakeMove(tokens[currentPlayer], moves[r.Intn(len(moves))])
+					if err != nil && !errors.As(err, &models.InvalidActionError{}) && !errors.As(err, &ConflictError{}) && !errors.As(err, &ForbiddenError{}) {
+						t.Error(err)
+						return
+					}
+				}
+			}(game, tokens[i], int64(i*movers+j))
+		}
+
+		for j := 0; j < readers; j++ {
+			wg.Add(1)
+			go func(game *Game) {
+				defer wg.Done()
+				for game.State().Status == GameStatusPlaying {
+					m.List()
+					game.History()
+				}
+			}(game)
+		}
+	}
+	wg.Wait()
+
+	for _, game := range games {
+		assert := assertions.New(t)
+		state := game.State()
+		assert.So(state.Status, should.Equal, GameStatusFinished)
+
+		// The moves were applied one at a time, so replaying the log gives the same game
+		replayed, err := game.log.Latest()
+		assert.So(err, should.BeNil)
+		assert.So(replayed.Snapshot().Public(), should.Resemble, state.State)
+	}
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	assert := assertions.New(t)
+
+	h := newHub()
+	slow := h.subscribe()
+	for i := 0; i <= subscriptionBufferSize; i++ {
+		h.broadcast(Message{Type: MessageTypeState})
+	}
+
+	received := 0
+	for range slow.Messages {
+		received++
+	}
+	assert.So(received, should.Equal, subscriptionBufferSize)
+	assert.So(h.subscriptions, should.BeEmpty)
+
+	// Cancelling after being dropped is harmless
+	slow.Cancel()
+}
@@ -0,0 +1,329 @@
+package manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Store keeps an append-only log of records for each game, so the games can be restored after
+// the server restarts
+type Store interface {
+	// Append adds the record to the end of the game's log
+	Append(gameID string, record Record) error
+	// Load returns the records of every game, in the order they were appended
+	Load() (map[string][]Record, error)
+}
+
+type RecordType string
+
+const (
+	// RecordTypeCreated is the first record of every game
+	RecordTypeCreated RecordType = "created"
+	// RecordTypeSeats is appended when the seats change, for example when a player joins
+	RecordTypeSeats RecordType = "seats"
+	// RecordTypeMove is appended for each move
+	RecordTypeMove RecordType = "move"
+)
+
+// Record is one change to a game. Which fields are set depends on the type:
+//
+//	created: Config, Seed, Clock (if the game is timed), CreatorToken, Seats and Tokens
+//	seats:   Seats and Tokens, as they are after the change, and Clocks (if the game is timed)
+//	move:    Move, and Clocks (if the game is timed) after the move
+type Record struct {
+	Type         RecordType
+	Config       *models.GameConfig `json:",omitempty"`
+	Seed         int64              `json:",omitempty"`
+	Clock        *ClockConfig       `json:",omitempty"`
+	CreatorToken string             `json:",omitempty"`
+	Seats        []Seat             `json:",omitempty"`
+	Tokens       map[int]string     `json:",omitempty"`
+	Move         string             `json:",omitempty"`
+	Clocks       []time.Duration    `json:",omitempty"`
+}
+
+// FileStore keeps each game's log in its own file in a directory, one JSON record per line.
+// Every record is synced to disk before Append returns, so a crash can lose at most the record
+// that was being written. A partly written record at the end of a file is dropped when the
+// logs are loaded.
+type FileStore struct {
+	dir   string
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+const fileStoreExtension = ".log"
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, files: make(map[string]*os.File)}, nil
+}
+
+func (s *FileStore) Append(gameID string, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, ok := s.files[gameID]
+	if !ok {
+		file, err = os.OpenFile(s.path(gameID), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		s.files[gameID] = file
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func (s *FileStore) Load() (map[string][]Record, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+fileStoreExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make(map[string][]Record, len(paths))
+	for _, path := range paths {
+		records, err := loadRecords(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		if len(records) > 0 {
+			logs[strings.TrimSuffix(filepath.Base(path), fileStoreExtension)] = records
+		}
+	}
+	return logs, nil
+}
+
+// loadRecords reads the records in the file. If the last record was only partly written, it's
+// cut off the end of the file, so the next record is appended after the last complete one.
+func loadRecords(path string) ([]Record, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []Record
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				// The server stopped in the middle of writing this record
+				return records, file.Truncate(offset)
+			}
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("record #%d isn't valid: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+		offset += int64(len(line))
+	}
+}
+
+// Close closes the files of the games that have been appended to
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result error
+	for gameID, file := range s.files {
+		if err := file.Close(); err != nil && result == nil {
+			result = err
+		}
+		delete(s.files, gameID)
+	}
+	return result
+}
+
+func (s *FileStore) path(gameID string) string {
+	return filepath.Join(s.dir, gameID+fileStoreExtension)
+}
+
+// Restore loads the games in the manager's store, and picks them up where they left off. The
+// clocks start again from the time that was left after the last move, so a player whose turn
+// it was when the server stopped gets back the time they'd used so far. It should be called
+// before the manager is used.
+func (m *Manager) Restore() error {
+	if m.store == nil {
+		return nil
+	}
+	logs, err := m.store.Load()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	restored := make([]*Game, 0, len(logs))
+	for id, records := range logs {
+		game, err := restoreGame(id, records)
+		if err != nil {
+			m.mu.Unlock()
+			return fmt.Errorf("failed to restore game '%s': %w", id, err)
+		}
+		game.store = m.store
+		game.logger = m.logger
+		m.games[id] = game
+		if n, err := strconv.Atoi(id); err == nil && n > m.nextID {
+			m.nextID = n
+		}
+		restored = append(restored, game)
+	}
+	m.mu.Unlock()
+
+	for _, game := range restored {
+		game.start()
+	}
+	return nil
+}
+
+// restoreGame rebuilds a game from its records
+func restoreGame(id string, records []Record) (*Game, error) {
+	created := records[0]
+	if created.Type != RecordTypeCreated || created.Config == nil {
+		return nil, fmt.Errorf("the first record must be a '%s' record with the game's config", RecordTypeCreated)
+	}
+	game, err := newGame(id, *created.Config, created.Seats, created.Seed, created.Clock, created.CreatorToken, created.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, record := range records[1:] {
+		switch record.Type {
+		case RecordTypeSeats:
+			if len(record.Seats) != len(game.seats) {
+				return nil, fmt.Errorf("record #%d has %d seats, but the game has %d", i+2, len(record.Seats), len(game.seats))
+			}
+			game.seats = record.Seats
+			for seat, s := range game.seats {
+				game.rename(seat, s.Name)
+			}
+			game.tokens = record.Tokens
+			if game.tokens == nil {
+				game.tokens = make(map[int]string)
+			}
+		case RecordTypeMove:
+			move, err := models.ParseMove(record.Move)
+			if err != nil {
+				return nil, fmt.Errorf("record #%d: %w", i+2, err)
+			}
+			if err := game.log.Record(game.game, move); err != nil {
+				return nil, fmt.Errorf("record #%d: %w", i+2, err)
+			}
+		default:
+			return nil, fmt.Errorf("record #%d has the unknown type '%s'", i+2, record.Type)
+		}
+		if game.clock != nil && len(record.Clocks) == len(game.clock.remaining) {
+			copy(game.clock.remaining, record.Clocks)
+		}
+	}
+
+	if game.bots, err = seatBots(game.seats); err != nil {
+		return nil, err
+	}
+	game.savedMoves = len(game.log.Moves)
+	game.savedSeats = append([]Seat{}, game.seats...)
+	game.savedTokens = copyTokens(game.tokens)
+	return game, nil
+}
+
+// saveCreated appends the game's first record to the store. It must be called before the
+// game's first change.
+func (g *Game) saveCreated() {
+	if g.store == nil {
+		return
+	}
+	config := g.log.Config
+	record := Record{
+		Type:         RecordTypeCreated,
+		Config:       &config,
+		Seed:         g.log.Seed,
+		CreatorToken: g.creatorToken,
+		Seats:        g.seats,
+		Tokens:       g.tokens,
+	}
+	if g.clock != nil {
+		clockConfig := g.clock.config
+		record.Clock = &clockConfig
+	}
+	g.append(record)
+	g.savedSeats = append([]Seat{}, g.seats...)
+	g.savedTokens = copyTokens(g.tokens)
+}
+
+// save appends a record to the store for each change since the last save. It must be called
+// while holding the write lock.
+func (g *Game) save() {
+	if g.store == nil {
+		return
+	}
+	for g.savedMoves < len(g.log.Moves) {
+		move := g.log.Moves[g.savedMoves]
+		if !g.append(Record{Type: RecordTypeMove, Move: move.String(), Clocks: g.savedClocks()}) {
+			return
+		}
+		g.savedMoves++
+	}
+	if !reflect.DeepEqual(g.seats, g.savedSeats) || !reflect.DeepEqual(g.tokens, g.savedTokens) {
+		if g.append(Record{Type: RecordTypeSeats, Seats: g.seats, Tokens: g.tokens, Clocks: g.savedClocks()}) {
+			g.savedSeats = append([]Seat{}, g.seats...)
+			g.savedTokens = copyTokens(g.tokens)
+		}
+	}
+}
+
+// append adds the record to the store. If it fails, the change is saved with the next one, and
+// the game's state tells the players that it hasn't been saved until then.
+func (g *Game) append(record Record) bool {
+	if err := g.store.Append(g.ID, record); err != nil {
+		g.logger.Printf("game %s: failed to save the game: %s", g.ID, err)
+		g.saveErr = err
+		return false
+	}
+	g.saveErr = nil
+	return true
+}
+
+// savedClocks returns the time left on each clock, not counting the time the running clock
+// has used so far, or nil if the game isn't timed
+func (g *Game) savedClocks() []time.Duration {
+	if g.clock == nil {
+		return nil
+	}
+	return append([]time.Duration{}, g.clock.remaining...)
+}
+
+func copyTokens(tokens map[int]string) map[int]string {
+	copied := make(map[int]string, len(tokens))
+	for seat, token := range tokens {
+		copied[seat] = token
+	}
+	return copied
+}
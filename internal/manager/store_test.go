@@ -0,0 +1,182 @@
+package manager
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+func TestFileStore_Load(t *testing.T) {
+	testCases := map[string]struct {
+		tail            string
+		expectedRecords int
+		expectedFile    string
+		expectedErr     bool
+	}{
+		"Complete records": {
+			expectedRecords: 2,
+			expectedFile:    "{\"Type\":\"created\"}\n{\"Type\":\"move\",\"Move\":\"F0:blue\\u003eL0\"}\n",
+		},
+		"A partly written record is cut off": {
+			tail:            `{"Type":"mo`,
+			expectedRecords: 2,
+			expectedFile:    "{\"Type\":\"created\"}\n{\"Type\":\"move\",\"Move\":\"F0:blue\\u003eL0\"}\n",
+		},
+		"A complete record that isn't valid": {
+			tail:        "{\"Type\":\n",
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			dir := t.TempDir()
+
+			store, err := NewFileStore(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.So(store.Append("1", Record{Type: RecordTypeCreated}), should.BeNil)
+			assert.So(store.Append("1", Record{Type: RecordTypeMove, Move: "F0:blue>L0"}), should.BeNil)
+			assert.So(store.Close(), should.BeNil)
+
+			file, err := os.OpenFile(filepath.Join(dir, "1.log"), os.O_WRONLY|os.O_APPEND, 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file.WriteString(tc.tail)
+			file.Close()
+
+			logs, err := store.Load()
+			if tc.expectedErr {
+				assert.So(err, should.NotBeNil)
+				return
+			}
+			assert.So(err, should.BeNil)
+			assert.So(logs["1"], should.HaveLength, tc.expectedRecords)
+			assert.So(logs["1"][1], should.Resemble, Record{Type: RecordTypeMove, Move: "F0:blue>L0"})
+
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "1.log"))
+			assert.So(err, should.BeNil)
+			assert.So(string(contents), should.Equal, tc.expectedFile)
+		})
+	}
+}
+
+func TestManager_Restore(t *testing.T) {
+	assert := assertions.New(t)
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := int64(7)
+	clock := ClockConfig{Base: time.Minute, Increment: time.Second, OnTimeout: TimeoutPolicyRandom}
+	m := NewManager(WithStore(store))
+	game, creatorToken, err := m.Create(models.DefaultGameConfig, 3, &seed, &clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, aliceToken, _ := game.Join(0, "alice")
+	_, bobToken, _ := game.Join(1, "bob")
+	_, err = game.ReplaceWithBot(creatorToken, 2, "greedy")
+	assert.So(err, should.BeNil)
+
+	// alice and bob take turns until it's alice's turn again, and the bot plays in between
+	tokens := map[int]string{0: aliceToken, 1: bobToken}
+	for i := 0; i < 4; i++ {
+		seat, moves := game.LegalMoves()
+		_, err := game.MakeMove(tokens[seat], moves[0])
+		assert.So(err, should.BeNil)
+	}
+	before := game.State()
+	assert.So(before.MoveCount, should.Equal, 6)
+	assert.So(store.Close(), should.BeNil)
+
+	restored := NewManager(WithStore(store))
+	assert.So(restored.Restore(), should.BeNil)
+	game, err = restored.Get(before.ID)
+	assert.So(err, should.BeNil)
+
+	after := game.State()
+	assert.So(after.Seats, should.Resemble, before.Seats)
+	assert.So(after.MoveCount, should.Equal, before.MoveCount)
+	assert.So(after.State, should.Resemble, before.State)
+	for seat, clockState := range after.Clocks {
+		if seat != after.CurrentPlayer {
+			assert.So(clockState.Remaining, should.Equal, before.Clocks[seat].Remaining)
+		}
+	}
+
+	// The tokens still work, and new games don't reuse the restored game's ID
+	seat, moves := game.LegalMoves()
+	_, err = game.MakeMove(tokens[seat], moves[0])
+	assert.So(err, should.BeNil)
+	_, err = game.Kick(creatorToken, 1)
+	assert.So(err, should.BeNil)
+	newGame, _, err := restored.Create(models.DefaultGameConfig, 2, nil, nil)
+	assert.So(err, should.BeNil)
+	assert.So(newGame.ID, should.NotEqual, before.ID)
+}
+
+// failingStore keeps the records in memory, and fails to append them while failing is set
+type failingStore struct {
+	failing bool
+	records map[string][]Record
+}
+
+func (s *failingStore) Append(gameID string, record Record) error {
+	if s.failing {
+		return errors.New("the disk is full")
+	}
+	s.records[gameID] = append(s.records[gameID], record)
+	return nil
+}
+
+func (s *failingStore) Load() (map[string][]Record, error) {
+	return s.records, nil
+}
+
+func TestGame_SaveFails(t *testing.T) {
+	assert := assertions.New(t)
+	store := &failingStore{records: make(map[string][]Record)}
+	var logged bytes.Buffer
+	m := NewManager(WithStore(store), WithLogger(log.New(&logged, "", 0)))
+	game, _, tokens := createJoinedGame(t, m, []string{"alice", "bob"})
+
+	// The players can see that the move wasn't saved
+	store.failing = true
+	_, moves := game.LegalMoves()
+	state, err := game.MakeMove(tokens[0], moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(state.SaveError, should.Equal, "the disk is full")
+	assert.So(logged.String(), should.Equal, "game 1: failed to save the game: the disk is full\n")
+
+	// The move is saved along with the next one
+	store.failing = false
+	_, moves = game.LegalMoves()
+	state, err = game.MakeMove(tokens[1], moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(state.SaveError, should.BeEmpty)
+	var saved []string
+	for _, record := range store.records[game.ID] {
+		if record.Type == RecordTypeMove {
+			saved = append(saved, record.Move)
+		}
+	}
+	players, history := game.History()
+	assert.So(players, should.Resemble, []string{"alice", "bob"})
+	assert.So(saved, should.Resemble, []string{history[0].String(), history[1].String()})
+}
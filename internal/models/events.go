@@ -0,0 +1,185 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EventType identifies the kind of an Event
+type EventType string
+
+const (
+	EventGameStarted            EventType = "GameStarted"
+	EventFactoriesFilled        EventType = "FactoriesFilled"
+	EventTilesDrawn             EventType = "TilesDrawn"
+	EventLeftoversMovedToCenter EventType = "LeftoversMovedToCenter"
+	EventTilesPlaced            EventType = "TilesPlaced"
+	EventFloorOverflow          EventType = "FloorOverflow"
+	EventWallTiled              EventType = "WallTiled"
+	EventFloorScored            EventType = "FloorScored"
+	EventRoundEnded             EventType = "RoundEnded"
+	EventGameEnded              EventType = "GameEnded"
+)
+
+// Event is something that happened in a game. Events are published to the game's observers
+// in the order they happen.
+type Event interface {
+	Type() EventType
+}
+
+// Observer receives the events published by a game
+type Observer interface {
+	HandleEvent(event Event)
+}
+
+// ObserverFunc lets an ordinary function be used as an Observer
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) HandleEvent(event Event) {
+	f(event)
+}
+
+type GameStarted struct {
+	Players     []string
+	FirstPlayer int
+}
+
+func (e GameStarted) Type() EventType { return EventGameStarted }
+
+type FactoriesFilled struct {
+	Round     int
+	Factories map[int][]Tile
+}
+
+func (e FactoriesFilled) Type() EventType { return EventFactoriesFilled }
+
+type TilesDrawn struct {
+	Player     int
+	PlayerName string
+	Move       Move
+	Tiles      []Tile
+}
+
+func (e TilesDrawn) Type() EventType { return EventTilesDrawn }
+
+type LeftoversMovedToCenter struct {
+	FactoryNumber int
+	Tiles         []Tile
+}
+
+func (e LeftoversMovedToCenter) Type() EventType { return EventLeftoversMovedToCenter }
+
+type TilesPlaced struct {
+	Player            int
+	PlayerName        string
+	PatternLineNumber int
+	Tiles             []Tile
+}
+
+func (e TilesPlaced) Type() EventType { return EventTilesPlaced }
+
+type FloorOverflow struct {
+	Player     int
+	PlayerName string
+	Tiles      []Tile
+}
+
+func (e FloorOverflow) Type() EventType { return EventFloorOverflow }
+
+type WallTiled struct {
+	Player            int
+	PlayerName        string
+	PatternLineNumber int
+	Tile              Tile
+	WallScore         WallScore
+}
+
+func (e WallTiled) Type() EventType { return EventWallTiled }
+
+type FloorScored struct {
+	Player     int
+	PlayerName string
+	Penalty    int
+	Tiles      []Tile
+
+	// The floor is the last of a player's board to be scored, so the event also has the
+	// player's score from before the round was scored, and after. The score never goes below
+	// 0, so the penalty can take away less than it says.
+	ScoreBefore int
+	ScoreAfter  int
+}
+
+func (e FloorScored) Type() EventType { return EventFloorScored }
+
+type RoundEnded struct {
+	Round       int
+	Scores      map[int]int
+	FirstPlayer int
+}
+
+func (e RoundEnded) Type() EventType { return EventRoundEnded }
+
+type GameEnded struct {
+	PlayerNames map[int]string
+	Scores      map[int]int
+	Bonuses     map[int]EndOfGameBonus
+	Winners     []int
+}
+
+func (e GameEnded) Type() EventType { return EventGameEnded }
+
+// DecodeEvent reads an event of the given type from JSON. The event is returned as a value,
+// just like the events a game publishes.
+func DecodeEvent(eventType EventType, data []byte) (Event, error) {
+	var event Event
+	var err error
+	switch eventType {
+	case EventGameStarted:
+		var e GameStarted
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventFactoriesFilled:
+		var e FactoriesFilled
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventTilesDrawn:
+		var e TilesDrawn
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventLeftoversMovedToCenter:
+		var e LeftoversMovedToCenter
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventTilesPlaced:
+		var e TilesPlaced
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventFloorOverflow:
+		var e FloorOverflow
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventWallTiled:
+		var e WallTiled
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventFloorScored:
+		var e FloorScored
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventRoundEnded:
+		var e RoundEnded
+		err = json.Unmarshal(data, &e)
+		event = e
+	case EventGameEnded:
+		var e GameEnded
+		err = json.Unmarshal(data, &e)
+		event = e
+	default:
+		return nil, fmt.Errorf("'%s' is not an event type", eventType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the %s event: %w", eventType, err)
+	}
+
+	return event, nil
+}
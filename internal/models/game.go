@@ -1,5 +1,11 @@
 package models
 
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
 type GameConfig struct {
 	TileColors         []TileColor
 	TilesPerColor      int
@@ -26,6 +32,73 @@ var DefaultGameConfig = GameConfig{
 	},
 }
 
+// The limits on a game config, which keep a game small enough to be set up and played. The
+// number of tile colors is always the number of columns on the wall.
+const (
+	MaxTilesPerColor   = 100
+	MaxTilesPerFactory = 10
+	MaxFactories       = 20
+	MaxPlayers         = 8
+)
+
+// ValidateNumberOfPlayers checks whether a game with this config can be played by the
+// number of players
+func (c GameConfig) ValidateNumberOfPlayers(numPlayers int) error {
+	if numPlayers < c.MinNumberOfPlayers || numPlayers > c.MaxNumberOfPlayers {
+		return InvalidActionError{Message: fmt.Sprintf("The game needs between %d and %d players", c.MinNumberOfPlayers, c.MaxNumberOfPlayers)}
+	}
+	if _, ok := c.PlayersToFactoriesMap[numPlayers]; !ok {
+		return InvalidActionError{Message: fmt.Sprintf("The game config doesn't say how many factories to use for %d players", numPlayers)}
+	}
+	return nil
+}
+
+// Validate checks that a game can be played with the config. The tiles must be the colors on
+// the wall, because a row of the wall can't be completed without all of them.
+func (c GameConfig) Validate() error {
+	if len(c.TileColors) != len(wallLayout[0]) {
+		return InvalidActionError{Message: fmt.Sprintf("The game needs %d tile colors, one for each column of the wall", len(wallLayout[0]))}
+	}
+	seen := make(map[TileColor]bool, len(c.TileColors))
+	for _, color := range c.TileColors {
+		onWall := false
+		for _, tile := range wallLayout[0] {
+			onWall = onWall || tile.Color == color
+		}
+		if !onWall {
+			return InvalidActionError{Message: fmt.Sprintf("'%s' isn't one of the colors on the wall", string(color))}
+		}
+		if seen[color] {
+			return InvalidActionError{Message: fmt.Sprintf("'%s' is in the tile colors twice", string(color))}
+		}
+		seen[color] = true
+	}
+	if c.TilesPerColor < 1 || c.TilesPerFactory < 1 {
+		return InvalidActionError{Message: "There must be at least one tile of each color, and one tile per factory"}
+	}
+	if c.TilesPerColor > MaxTilesPerColor || c.TilesPerFactory > MaxTilesPerFactory {
+		return InvalidActionError{Message: fmt.Sprintf("There can be at most %d tiles of each color, and %d tiles per factory", MaxTilesPerColor, MaxTilesPerFactory)}
+	}
+	if c.MinNumberOfPlayers < 1 || c.MinNumberOfPlayers > c.MaxNumberOfPlayers {
+		return InvalidActionError{Message: fmt.Sprintf("The game can't be played by between %d and %d players", c.MinNumberOfPlayers, c.MaxNumberOfPlayers)}
+	}
+	if c.MaxNumberOfPlayers > MaxPlayers {
+		return InvalidActionError{Message: fmt.Sprintf("The game can be played by at most %d players", MaxPlayers)}
+	}
+	for numPlayers := c.MinNumberOfPlayers; numPlayers <= c.MaxNumberOfPlayers; numPlayers++ {
+		if err := c.ValidateNumberOfPlayers(numPlayers); err != nil {
+			return err
+		}
+		if c.PlayersToFactoriesMap[numPlayers] < 1 {
+			return InvalidActionError{Message: fmt.Sprintf("The game needs at least one factory for %d players", numPlayers)}
+		}
+		if c.PlayersToFactoriesMap[numPlayers] > MaxFactories {
+			return InvalidActionError{Message: fmt.Sprintf("The game can have at most %d factories", MaxFactories)}
+		}
+	}
+	return nil
+}
+
 type Game struct {
 	Config           GameConfig
 	Players          map[int]Player
@@ -33,12 +106,24 @@ type Game struct {
 	CenterOfTheTable *TileCollection
 	Bag              *Bag
 	DiscardPile      []Tile
+
+	// Seed determines how tiles are drawn from the bag. Two games with the same config,
+	// players, seed and moves end up in exactly the same state.
+	Seed             int64
+	Round            int
+	CurrentPlayerKey int
+	GameOver         bool
+
+	observers []Observer
 }
 
 func NewGame(opts ...NewGameOption) *Game {
 	g := &Game{
-		Config:  DefaultGameConfig,
-		Players: make(map[int]Player),
+		Config:      DefaultGameConfig,
+		Players:     make(map[int]Player),
+		DiscardPile: make([]Tile, 0),
+		Seed:        time.Now().UnixNano(),
+		Round:       1,
 	}
 
 	for _, opt := range opts {
@@ -46,8 +131,16 @@ func NewGame(opts ...NewGameOption) *Game {
 	}
 
 	g.ResetBag()
-	g.ResetFactories()
 	g.ResetCenterOfTheTable()
+	g.CurrentPlayerKey = g.firstPlayerKey()
+
+	names := make([]string, len(g.Players))
+	for i := 0; i < len(g.Players); i++ {
+		names[i] = g.Players[i].Name
+	}
+	g.publish(GameStarted{Players: names, FirstPlayer: g.CurrentPlayerKey})
+
+	g.ResetFactories()
 
 	return g
 }
@@ -68,13 +161,65 @@ func WithPlayers(players map[int]Player) NewGameOption {
 	}
 }
 
+func WithSeed(seed int64) NewGameOption {
+	return func(g *Game) {
+		g.Seed = seed
+	}
+}
+
+// WithObserver subscribes the observer before the game is set up, so it also receives the
+// GameStarted and FactoriesFilled events for the first round.
+func WithObserver(observer Observer) NewGameOption {
+	return func(g *Game) {
+		g.Subscribe(observer)
+	}
+}
+
+// Subscribe registers an observer that receives every event the game publishes from now on
+func (g *Game) Subscribe(observer Observer) {
+	g.observers = append(g.observers, observer)
+}
+
+func (g *Game) publish(event Event) {
+	for _, observer := range g.observers {
+		observer.HandleEvent(event)
+	}
+}
+
+func (g *Game) CurrentPlayer() Player {
+	return g.Players[g.CurrentPlayerKey]
+}
+
+func (g *Game) firstPlayerKey() int {
+	for i := 0; i < len(g.Players); i++ {
+		if g.Players[i].IsFirstPlayer {
+			return i
+		}
+	}
+	return 0
+}
+
+// ResetFactories fills every factory with tiles drawn from the bag. When the bag runs out,
+// the discard pile is put back in the bag. If both are empty, the remaining factories stay
+// partially filled, or empty.
 func (g *Game) ResetFactories() {
+	g.Bag.random = rand.New(rand.NewSource(g.Seed + int64(g.Round)))
+
 	numFactories := g.Config.PlayersToFactoriesMap[len(g.Players)]
 	g.Factories = make(map[int]*Factory, numFactories)
+	filled := make(map[int][]Tile, numFactories)
 
 	for i := 0; i < numFactories; i++ {
 		factory := NewFactory()
 		for t := 0; t < g.Config.TilesPerFactory; t++ {
+			if !g.Bag.HasTiles() {
+				g.Bag.AddTiles(g.DiscardPile)
+				g.DiscardPile = make([]Tile, 0)
+			}
+			if !g.Bag.HasTiles() {
+				break
+			}
+
 			tile, err := g.Bag.DrawRandomTile()
 			if err != nil {
 				panic(err)
@@ -83,7 +228,10 @@ func (g *Game) ResetFactories() {
 		}
 
 		g.Factories[i] = factory
+		filled[i] = append([]Tile{}, factory.Tiles...)
 	}
+
+	g.publish(FactoriesFilled{Round: g.Round, Factories: filled})
 }
 
 func (g *Game) ResetBag() {
@@ -103,9 +251,271 @@ func (g *Game) ResetCenterOfTheTable() {
 	g.CenterOfTheTable.AddTile(Tile{Color: FirstPlayerTile})
 }
 
+// ValidateMove checks whether the current player is allowed to make the move
+func (g *Game) ValidateMove(move Move) error {
+	if g.GameOver {
+		return InvalidActionError{Message: "The game is over"}
+	}
+
+	if !g.isTileColor(move.TileColor) {
+		return InvalidActionError{Message: fmt.Sprintf("'%s' is not a tile color", string(move.TileColor))}
+	}
+
+	switch move.DrawSourceType {
+	case DrawSourceFactory:
+		factory, ok := g.Factories[move.FactoryNumber]
+		if !ok {
+			return InvalidActionError{Message: fmt.Sprintf("There is no factory #%d", move.FactoryNumber)}
+		}
+		if !factory.HasTilesOfColor(move.TileColor) {
+			return InvalidActionError{Message: fmt.Sprintf("There are no %s tiles on factory #%d", string(move.TileColor), move.FactoryNumber)}
+		}
+	case DrawSourceCenter:
+		if !g.CenterOfTheTable.HasTilesOfColor(move.TileColor) {
+			return InvalidActionError{Message: fmt.Sprintf("There are no %s tiles in the center of the table", string(move.TileColor))}
+		}
+	default:
+		return InvalidActionError{Message: fmt.Sprintf("'%s' is not a draw source, please choose '%s' or '%s'", string(move.DrawSourceType), DrawSourceFactory, DrawSourceCenter)}
+	}
+
+	return g.CurrentPlayer().Board.ValidatePlacement(move.PatternLineNumber, move.TileColor)
+}
+
+func (g *Game) isTileColor(color TileColor) bool {
+	for _, c := range g.Config.TileColors {
+		if c == color {
+			return true
+		}
+	}
+	return false
+}
+
+// TakeTurn makes the move for the current player, and then passes the turn to the next
+// player. When the move empties the last factory and the center of the table, the round is
+// scored and the next round is set up, or the game ends.
+func (g *Game) TakeTurn(move Move) error {
+	if err := g.ValidateMove(move); err != nil {
+		return err
+	}
+
+	playerKey := g.CurrentPlayerKey
+	player := g.Players[playerKey]
+
+	// Draw tiles from a factory or the center of the table
+	var drawSource DrawSource = g.CenterOfTheTable
+	if move.DrawSourceType == DrawSourceFactory {
+		drawSource = g.Factories[move.FactoryNumber]
+	}
+	drawnTiles, err := drawSource.DrawAllTilesByColor(move.TileColor)
+	if err != nil {
+		return err
+	}
+	g.publish(TilesDrawn{Player: playerKey, PlayerName: player.Name, Move: move, Tiles: drawnTiles})
+
+	// Put the rest of the factory's tiles in the center of the table
+	if move.DrawSourceType == DrawSourceFactory {
+		leftovers := g.Factories[move.FactoryNumber].DrawAllTiles()
+		if len(leftovers) > 0 {
+			g.CenterOfTheTable.AddTiles(leftovers)
+			g.publish(LeftoversMovedToCenter{FactoryNumber: move.FactoryNumber, Tiles: leftovers})
+		}
+	}
+
+	// Put the drawn tiles onto the player's game board (and/or floor)
+	overflow, err := player.Board.PlaceTiles(move.PatternLineNumber, drawnTiles)
+	if err != nil {
+		return err
+	}
+	g.publish(TilesPlaced{Player: playerKey, PlayerName: player.Name, PatternLineNumber: move.PatternLineNumber, Tiles: drawnTiles})
+	if len(overflow) > 0 {
+		g.DiscardPile = append(g.DiscardPile, overflow...)
+		g.publish(FloorOverflow{Player: playerKey, PlayerName: player.Name, Tiles: overflow})
+	}
+
+	if g.IsRoundOver() {
+		g.endRound()
+	} else {
+		g.CurrentPlayerKey = (g.CurrentPlayerKey + 1) % len(g.Players)
+	}
+
+	return nil
+}
+
+// IsRoundOver returns true when there are no more tiles to draw from the factories or the
+// center of the table
+func (g *Game) IsRoundOver() bool {
+	for _, factory := range g.Factories {
+		if factory.HasTiles() {
+			return false
+		}
+	}
+
+	for _, tile := range g.CenterOfTheTable.Tiles {
+		if tile.Color != FirstPlayerTile {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ScoreRound moves the tiles from each player's full pattern lines to their wall, scores
+// them, and takes the floor penalties. Whoever has the first player tile on their floor
+// becomes the first player for the next round.
 func (g *Game) ScoreRound() {
-	for _, player := range g.Players {
-		player.Board.ScorePatternLines()
-		player.Board.ScoreFloor()
+	nextFirstPlayer := -1
+
+	for i := 0; i < len(g.Players); i++ {
+		player := g.Players[i]
+		scoreBefore := player.Board.Score
+
+		for _, tiling := range player.Board.ScorePatternLines() {
+			g.DiscardPile = append(g.DiscardPile, tiling.DiscardedTiles...)
+			g.publish(WallTiled{
+				Player:            i,
+				PlayerName:        player.Name,
+				PatternLineNumber: tiling.PatternLineNumber,
+				Tile:              tiling.Tile,
+				WallScore:         tiling.WallScore,
+			})
+		}
+
+		floorScore := player.Board.ScoreFloor()
+		for _, tile := range floorScore.Tiles {
+			if tile.Color == FirstPlayerTile {
+				nextFirstPlayer = i
+			} else {
+				g.DiscardPile = append(g.DiscardPile, tile)
+			}
+		}
+		g.publish(FloorScored{
+			Player:      i,
+			PlayerName:  player.Name,
+			Penalty:     floorScore.Penalty,
+			Tiles:       floorScore.Tiles,
+			ScoreBefore: scoreBefore,
+			ScoreAfter:  player.Board.Score,
+		})
+	}
+
+	if nextFirstPlayer >= 0 {
+		for i := 0; i < len(g.Players); i++ {
+			player := g.Players[i]
+			player.IsFirstPlayer = i == nextFirstPlayer
+			g.Players[i] = player
+		}
+	}
+}
+
+func (g *Game) endRound() {
+	g.ScoreRound()
+
+	firstPlayer := g.firstPlayerKey()
+	g.publish(RoundEnded{Round: g.Round, Scores: g.Scores(), FirstPlayer: firstPlayer})
+
+	for i := 0; i < len(g.Players); i++ {
+		if g.Players[i].Board.HasCompleteRow() {
+			g.endGame()
+			return
+		}
+	}
+
+	g.Round++
+	g.CurrentPlayerKey = firstPlayer
+	g.ResetCenterOfTheTable()
+	g.ResetFactories()
+
+	// The bag and the discard pile can run out when there are few tiles, and then there's
+	// nothing left to draw
+	if g.IsRoundOver() {
+		g.endGame()
+	}
+}
+
+func (g *Game) endGame() {
+	names := make(map[int]string, len(g.Players))
+	bonuses := make(map[int]EndOfGameBonus, len(g.Players))
+	for i := 0; i < len(g.Players); i++ {
+		names[i] = g.Players[i].Name
+		bonuses[i] = g.Players[i].Board.ScoreEndOfGame()
 	}
+	g.GameOver = true
+
+	g.publish(GameEnded{PlayerNames: names, Scores: g.Scores(), Bonuses: bonuses, Winners: g.Winners()})
+}
+
+// Scores returns each player's current score
+func (g *Game) Scores() map[int]int {
+	scores := make(map[int]int, len(g.Players))
+	for i, player := range g.Players {
+		scores[i] = player.Board.Score
+	}
+	return scores
+}
+
+// Winners returns the players with the highest score. Ties are broken by the number of
+// complete rows on the wall, and players who are still tied share the victory.
+func (g *Game) Winners() []int {
+	players := make([]int, len(g.Players))
+	for i := range players {
+		players[i] = i
+	}
+	return g.WinnersAmong(players)
+}
+
+// WinnersAmong returns the winners when only some of the players can win, for example
+// because the others have forfeited. The players must be in order.
+func (g *Game) WinnersAmong(players []int) []int {
+	winners := make([]int, 0)
+	bestScore, bestRows := -1, -1
+
+	for _, i := range players {
+		score := g.Players[i].Board.Score
+		rows := g.Players[i].Board.CompleteRows()
+
+		if score > bestScore || (score == bestScore && rows > bestRows) {
+			winners = []int{i}
+			bestScore, bestRows = score, rows
+		} else if score == bestScore && rows == bestRows {
+			winners = append(winners, i)
+		}
+	}
+
+	return winners
+}
+
+// LegalMoves returns every move the current player is allowed to make
+func (g *Game) LegalMoves() []Move {
+	moves := make([]Move, 0)
+	if g.GameOver {
+		return moves
+	}
+
+	sources := make([]Move, 0, len(g.Factories)+1)
+	for i := 0; i < len(g.Factories); i++ {
+		sources = append(sources, Move{DrawSourceType: DrawSourceFactory, FactoryNumber: i})
+	}
+	sources = append(sources, Move{DrawSourceType: DrawSourceCenter})
+
+	for _, source := range sources {
+		for _, color := range g.Config.TileColors {
+			for line := 0; line < NumPatternLines; line++ {
+				move := source
+				move.TileColor = color
+				move.PatternLineNumber = line
+				if g.ValidateMove(move) == nil {
+					moves = append(moves, move)
+				}
+			}
+
+			move := source
+			move.TileColor = color
+			move.PatternLineNumber = FloorLine
+			if g.ValidateMove(move) == nil {
+				moves = append(moves, move)
+			}
+		}
+	}
+
+	return moves
 }
@@ -0,0 +1,353 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/testutils"
+)
+
+type eventRecorder struct {
+	events []Event
+}
+
+func (r *eventRecorder) HandleEvent(event Event) {
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) types() []EventType {
+	result := make([]EventType, 0, len(r.events))
+	for _, event := range r.events {
+		result = append(result, event.Type())
+	}
+	return result
+}
+
+func newTestGame(opts ...NewGameOption) *Game {
+	players := map[int]Player{
+		0: NewPlayer("alice", FirstPlayer()),
+		1: NewPlayer("bob"),
+	}
+	opts = append([]NewGameOption{WithPlayers(players), WithSeed(1)}, opts...)
+	return NewGame(opts...)
+}
+
+// setFactories replaces the tiles on the game's factories. Factories that aren't
+// specified are emptied.
+func setFactories(g *Game, factories map[int][]Tile) {
+	for i, factory := range g.Factories {
+		factory.Tiles = append([]Tile{}, factories[i]...)
+	}
+}
+
+func TestGameConfig_Validate(t *testing.T) {
+	testCases := map[string]struct {
+		change      func(c *GameConfig)
+		expectedErr error
+	}{
+		"The default config": {
+			change: func(c *GameConfig) {},
+		},
+		"Error case: a color is missing": {
+			change:      func(c *GameConfig) { c.TileColors = c.TileColors[1:] },
+			expectedErr: InvalidActionError{Message: "The game needs 5 tile colors, one for each column of the wall"},
+		},
+		"Error case: a color isn't on the wall": {
+			change:      func(c *GameConfig) { c.TileColors = []TileColor{Orange, Blue, White, Black, "green"} },
+			expectedErr: InvalidActionError{Message: "'green' isn't one of the colors on the wall"},
+		},
+		"Error case: a color is there twice": {
+			change:      func(c *GameConfig) { c.TileColors = []TileColor{Orange, Blue, White, Black, Black} },
+			expectedErr: InvalidActionError{Message: "'black' is in the tile colors twice"},
+		},
+		"Error case: no tiles per factory": {
+			change:      func(c *GameConfig) { c.TilesPerFactory = 0 },
+			expectedErr: InvalidActionError{Message: "There must be at least one tile of each color, and one tile per factory"},
+		},
+		"Error case: too many tiles of each color": {
+			change:      func(c *GameConfig) { c.TilesPerColor = 1000000 },
+			expectedErr: InvalidActionError{Message: "There can be at most 100 tiles of each color, and 10 tiles per factory"},
+		},
+		"Error case: too many tiles per factory": {
+			change:      func(c *GameConfig) { c.TilesPerFactory = 11 },
+			expectedErr: InvalidActionError{Message: "There can be at most 100 tiles of each color, and 10 tiles per factory"},
+		},
+		"Error case: too many players": {
+			change:      func(c *GameConfig) { c.MaxNumberOfPlayers = 9 },
+			expectedErr: InvalidActionError{Message: "The game can be played by at most 8 players"},
+		},
+		"Error case: more players than factory counts": {
+			change:      func(c *GameConfig) { c.MaxNumberOfPlayers = 5 },
+			expectedErr: InvalidActionError{Message: "The game config doesn't say how many factories to use for 5 players"},
+		},
+		"Error case: no factories": {
+			change:      func(c *GameConfig) { c.PlayersToFactoriesMap = map[int]int{2: 5, 3: 0, 4: 9} },
+			expectedErr: InvalidActionError{Message: "The game needs at least one factory for 3 players"},
+		},
+		"Error case: too many factories": {
+			change:      func(c *GameConfig) { c.PlayersToFactoriesMap = map[int]int{2: 5, 3: 7, 4: 1000} },
+			expectedErr: InvalidActionError{Message: "The game can have at most 20 factories"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			config := DefaultGameConfig
+			config.TileColors = append([]TileColor{}, config.TileColors...)
+			tc.change(&config)
+
+			assert.So(config.Validate(), testutils.ShouldEqualError, tc.expectedErr)
+		})
+	}
+}
+
+func TestGame_ValidateMove(t *testing.T) {
+	type state struct {
+		factories map[int][]Tile
+		move      Move
+	}
+	type expected struct {
+		err error
+	}
+	testCases := map[string]struct {
+		state    state
+		expected expected
+	}{
+		"Error case: the factory doesn't exist": {
+			state{
+				move: Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 12, TileColor: Blue},
+			},
+			expected{
+				err: InvalidActionError{Message: "There is no factory #12"},
+			},
+		},
+		"Error case: the factory doesn't have the color": {
+			state{
+				factories: map[int][]Tile{0: {{Color: Red}}},
+				move:      Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue},
+			},
+			expected{
+				err: InvalidActionError{Message: "There are no blue tiles on factory #0"},
+			},
+		},
+		"Error case: the center doesn't have the color": {
+			state{
+				move: Move{DrawSourceType: DrawSourceCenter, TileColor: Blue},
+			},
+			expected{
+				err: InvalidActionError{Message: "There are no blue tiles in the center of the table"},
+			},
+		},
+		"Error case: the first player tile can't be drawn by itself": {
+			state{
+				move: Move{DrawSourceType: DrawSourceCenter, TileColor: FirstPlayerTile},
+			},
+			expected{
+				err: InvalidActionError{Message: "'1stplayer' is not a tile color"},
+			},
+		},
+		"Error case: unknown draw source": {
+			state{
+				move: Move{DrawSourceType: "bag", TileColor: Blue},
+			},
+			expected{
+				err: InvalidActionError{Message: "'bag' is not a draw source"},
+			},
+		},
+		"Error case: the pattern line doesn't exist": {
+			state{
+				factories: map[int][]Tile{0: {{Color: Blue}}},
+				move:      Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue, PatternLineNumber: 5},
+			},
+			expected{
+				err: InvalidActionError{Message: "There is no pattern line #5"},
+			},
+		},
+		"Placing on a pattern line": {
+			state{
+				factories: map[int][]Tile{0: {{Color: Blue}}},
+				move:      Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue, PatternLineNumber: 2},
+			},
+			expected{},
+		},
+		"Placing on the floor": {
+			state{
+				factories: map[int][]Tile{0: {{Color: Blue}}},
+				move:      Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue, PatternLineNumber: FloorLine},
+			},
+			expected{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+
+			g := newTestGame()
+			setFactories(g, tc.state.factories)
+
+			err := g.ValidateMove(tc.state.move)
+
+			assert.So(err, testutils.ShouldEqualError, tc.expected.err)
+		})
+	}
+}
+
+func TestGame_TakeTurn(t *testing.T) {
+	assert := assertions.New(t)
+
+	recorder := &eventRecorder{}
+	g := newTestGame(WithObserver(recorder))
+	assert.So(recorder.types(), should.Resemble, []EventType{EventGameStarted, EventFactoriesFilled})
+
+	setFactories(g, map[int][]Tile{
+		0: {{Color: Blue}, {Color: Blue}, {Color: Red}, {Color: White}},
+		1: {{Color: Red}},
+	})
+	recorder.events = nil
+
+	err := g.TakeTurn(Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue, PatternLineNumber: 1})
+
+	assert.So(err, should.BeNil)
+	assert.So(recorder.types(), should.Resemble, []EventType{EventTilesDrawn, EventLeftoversMovedToCenter, EventTilesPlaced})
+	assert.So(g.Players[0].Board.PatternLines[1], shouldEqualTileSlice, []Tile{{Color: Blue}, {Color: Blue}})
+	assert.So(g.CenterOfTheTable.Tiles, shouldEqualTileSlice, []Tile{{Color: FirstPlayerTile}, {Color: Red}, {Color: White}})
+	assert.So(g.CurrentPlayerKey, should.Equal, 1)
+
+	// Bob takes the first player tile along with the reds, which overflows line 0 onto the floor
+	recorder.events = nil
+	err = g.TakeTurn(Move{DrawSourceType: DrawSourceCenter, TileColor: Red, PatternLineNumber: 0})
+
+	assert.So(err, should.BeNil)
+	assert.So(recorder.types(), should.Resemble, []EventType{EventTilesDrawn, EventTilesPlaced})
+	assert.So(g.Players[1].Board.PatternLines[0], shouldEqualTileSlice, []Tile{{Color: Red}})
+	assert.So(len(g.Players[1].Board.Floor), should.Equal, 1)
+	assert.So(g.CurrentPlayerKey, should.Equal, 0)
+}
+
+func TestGame_TakeTurn_EndOfRound(t *testing.T) {
+	assert := assertions.New(t)
+
+	recorder := &eventRecorder{}
+	g := newTestGame(WithObserver(recorder))
+	setFactories(g, map[int][]Tile{
+		0: {{Color: Blue}},
+		1: {{Color: Red}},
+	})
+	g.CenterOfTheTable.AddTile(Tile{Color: White})
+
+	assert.So(g.TakeTurn(Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue, PatternLineNumber: 0}), should.BeNil)
+	assert.So(g.TakeTurn(Move{DrawSourceType: DrawSourceCenter, TileColor: White, PatternLineNumber: 0}), should.BeNil)
+
+	recorder.events = nil
+	assert.So(g.TakeTurn(Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 1, TileColor: Red, PatternLineNumber: 1}), should.BeNil)
+
+	assert.So(recorder.types(), should.Resemble, []EventType{
+		EventTilesDrawn, EventTilesPlaced,
+		EventWallTiled, EventFloorScored, // alice
+		EventWallTiled, EventFloorScored, // bob
+		EventRoundEnded, EventFactoriesFilled,
+	})
+	assert.So(g.Round, should.Equal, 2)
+	assert.So(g.Players[0].Board.Score, should.Equal, 1)
+	assert.So(g.Players[0].Board.PatternLines[1], shouldEqualTileSlice, []Tile{{Color: Red}})
+	// Bob's wall tile is cancelled out by the first player tile on their floor
+	assert.So(g.Players[1].Board.Score, should.Equal, 0)
+	bobsFloor := recorder.events[5].(FloorScored)
+	assert.So(bobsFloor.Penalty, should.Equal, -1)
+	assert.So(bobsFloor.ScoreBefore, should.Equal, 0)
+	assert.So(bobsFloor.ScoreAfter, should.Equal, 0)
+	assert.So(g.Players[1].IsFirstPlayer, should.BeTrue)
+	assert.So(g.Players[0].IsFirstPlayer, should.BeFalse)
+	assert.So(g.CurrentPlayerKey, should.Equal, 1)
+	assert.So(g.CenterOfTheTable.Tiles, should.Resemble, []Tile{{Color: FirstPlayerTile}})
+	assert.So(g.IsRoundOver(), should.BeFalse)
+}
+
+func TestGame_Snapshot_SharesNoMemory(t *testing.T) {
+	assert := assertions.New(t)
+	g := newTestGame()
+	snapshot := g.Snapshot()
+
+	snapshot.Config.TileColors[0] = "green"
+	snapshot.Config.PlayersToFactoriesMap[2] = 1
+	assert.So(g.Config.TileColors[0], should.Equal, Orange)
+	assert.So(g.Config.PlayersToFactoriesMap[2], should.Equal, 5)
+	assert.So(DefaultGameConfig.TileColors[0], should.Equal, Orange)
+
+	restored := RestoreGame(g.Snapshot())
+	restored.Config.TileColors[0] = "green"
+	assert.So(g.Config.TileColors[0], should.Equal, Orange)
+}
+
+func TestGame_TakeTurn_FirstPlayerTileOnAFullFloor(t *testing.T) {
+	assert := assertions.New(t)
+	g := newTestGame()
+	setFactories(g, map[int][]Tile{0: {{Color: Blue}}})
+	g.CenterOfTheTable.AddTile(Tile{Color: Red})
+	alice := g.Players[0].Board
+	alice.AddToFloor([]Tile{{Color: Black}, {Color: Black}, {Color: Black}, {Color: Black}, {Color: Black}, {Color: Black}, {Color: White}})
+	g.Players[0] = Player{Name: "alice", Board: alice}
+	discarded := len(g.DiscardPile)
+
+	// alice is the first to draw from the center, so she takes the first player tile, and
+	// it takes the place of the last tile on her full floor
+	assert.So(g.TakeTurn(Move{DrawSourceType: DrawSourceCenter, TileColor: Red, PatternLineNumber: 0}), should.BeNil)
+	assert.So(len(alice.Floor), should.Equal, NumFloorSpaces)
+	assert.So(alice.Floor[NumFloorSpaces-1].Tile.Color, should.Equal, FirstPlayerTile)
+	assert.So(g.DiscardPile[discarded:], shouldEqualTileSlice, []Tile{{Color: White}})
+
+	assert.So(g.TakeTurn(Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 0, TileColor: Blue, PatternLineNumber: 0}), should.BeNil)
+	assert.So(g.Round, should.Equal, 2)
+	assert.So(g.Players[0].IsFirstPlayer, should.BeTrue)
+	assert.So(g.CurrentPlayerKey, should.Equal, 0)
+
+	// There's still only one first player tile, in the center of the table
+	firstPlayerTiles := 0
+	tiles := append(append(append([]Tile{}, g.Bag.Tiles...), g.DiscardPile...), g.CenterOfTheTable.Tiles...)
+	for _, factory := range g.Factories {
+		tiles = append(tiles, factory.Tiles...)
+	}
+	for _, tile := range tiles {
+		if tile.Color == FirstPlayerTile {
+			firstPlayerTiles++
+		}
+	}
+	assert.So(firstPlayerTiles, should.Equal, 1)
+}
+
+func TestGame_TakeTurn_TilesRunOut(t *testing.T) {
+	assert := assertions.New(t)
+	config := GameConfig{
+		TileColors:            []TileColor{Orange, Blue, White, Black, Red},
+		TilesPerColor:         1,
+		TilesPerFactory:       1,
+		MinNumberOfPlayers:    2,
+		MaxNumberOfPlayers:    2,
+		PlayersToFactoriesMap: map[int]int{2: 1},
+	}
+	assert.So(config.Validate(), should.BeNil)
+	g := newTestGame(WithConfig(config))
+
+	// Each tile goes on the longest pattern line that can take it, where it stays, so the
+	// tiles run out before anyone completes a row
+	for turns := 0; !g.GameOver && turns < 100; turns++ {
+		moves := g.LegalMoves()
+		assert.So(moves, should.NotBeEmpty)
+		if len(moves) == 0 {
+			break
+		}
+		move := moves[0]
+		for _, m := range moves {
+			if m.PatternLineNumber > move.PatternLineNumber {
+				move = m
+			}
+		}
+		assert.So(g.TakeTurn(move), should.BeNil)
+	}
+	assert.So(g.GameOver, should.BeTrue)
+	assert.So(g.Players[0].Board.HasCompleteRow() || g.Players[1].Board.HasCompleteRow(), should.BeFalse)
+}
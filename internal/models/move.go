@@ -0,0 +1,87 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Move is a single turn: a player draws all the tiles of one color from a factory or from
+// the center of the table, and places them on one of their pattern lines (or the floor).
+type Move struct {
+	DrawSourceType    DrawSourceType
+	FactoryNumber     int
+	TileColor         TileColor
+	PatternLineNumber int
+}
+
+// String writes the move in notation, for example "F2:blue>L3" to draw the blue tiles from
+// factory #2 and place them on pattern line #3, or "C:red>floor" to draw the red tiles from
+// the center of the table and place them on the floor.
+func (m Move) String() string {
+	source := "C"
+	if m.DrawSourceType == DrawSourceFactory {
+		source = fmt.Sprintf("F%d", m.FactoryNumber)
+	}
+
+	destination := "floor"
+	if m.PatternLineNumber != FloorLine {
+		destination = fmt.Sprintf("L%d", m.PatternLineNumber)
+	}
+
+	return fmt.Sprintf("%s:%s>%s", source, string(m.TileColor), destination)
+}
+
+// ParseMove reads a move written in notation (see Move.String). It doesn't check whether the
+// move is legal in any particular game.
+func ParseMove(notation string) (Move, error) {
+	move := Move{}
+	invalid := func(reason string) (Move, error) {
+		return Move{}, InvalidActionError{Message: fmt.Sprintf("'%s' is not a valid move: %s", notation, reason)}
+	}
+
+	s := strings.ToLower(strings.TrimSpace(notation))
+	sourceAndRest := strings.SplitN(s, ":", 2)
+	if len(sourceAndRest) != 2 {
+		return invalid("expected a draw source followed by ':'")
+	}
+	colorAndDestination := strings.SplitN(sourceAndRest[1], ">", 2)
+	if len(colorAndDestination) != 2 {
+		return invalid("expected a tile color followed by '>'")
+	}
+	source, color, destination := sourceAndRest[0], colorAndDestination[0], colorAndDestination[1]
+
+	switch {
+	case source == "c":
+		move.DrawSourceType = DrawSourceCenter
+	case strings.HasPrefix(source, "f"):
+		factoryNumber, err := strconv.Atoi(source[1:])
+		if err != nil || factoryNumber < 0 {
+			return invalid(fmt.Sprintf("'%s' is not a factory", source))
+		}
+		move.DrawSourceType = DrawSourceFactory
+		move.FactoryNumber = factoryNumber
+	default:
+		return invalid(fmt.Sprintf("'%s' is not a draw source, expected 'C' or 'F<number>'", source))
+	}
+
+	if color == "" {
+		return invalid("the tile color is missing")
+	}
+	move.TileColor = TileColor(color)
+
+	switch {
+	case destination == "floor":
+		move.PatternLineNumber = FloorLine
+	case strings.HasPrefix(destination, "l"):
+		lineNumber, err := strconv.Atoi(destination[1:])
+		if err != nil || lineNumber < 0 {
+			return invalid(fmt.Sprintf("'%s' is not a pattern line", destination))
+		}
+		move.PatternLineNumber = lineNumber
+	default:
+		return invalid(fmt.Sprintf("'%s' is not a destination, expected 'L<number>' or 'floor'", destination))
+	}
+
+	return move, nil
+}
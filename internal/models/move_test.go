@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/testutils"
+)
+
+func TestParseMove(t *testing.T) {
+	type expected struct {
+		move Move
+		err  error
+	}
+	testCases := map[string]struct {
+		notation string
+		expected expected
+	}{
+		"Factory to pattern line": {
+			notation: "F2:blue>L3",
+			expected: expected{
+				move: Move{DrawSourceType: DrawSourceFactory, FactoryNumber: 2, TileColor: Blue, PatternLineNumber: 3},
+			},
+		},
+		"Center to floor, lower case with spaces": {
+			notation: " c:red>floor\n",
+			expected: expected{
+				move: Move{DrawSourceType: DrawSourceCenter, TileColor: Red, PatternLineNumber: FloorLine},
+			},
+		},
+		"Error case: missing source": {
+			notation: "blue>L3",
+			expected: expected{
+				err: InvalidActionError{Message: "'blue>L3' is not a valid move: expected a draw source followed by ':'"},
+			},
+		},
+		"Error case: bad factory number": {
+			notation: "Fx:blue>L3",
+			expected: expected{
+				err: InvalidActionError{Message: "'Fx:blue>L3' is not a valid move: 'fx' is not a factory"},
+			},
+		},
+		"Error case: bad destination": {
+			notation: "C:blue>wall",
+			expected: expected{
+				err: InvalidActionError{Message: "'C:blue>wall' is not a valid move: 'wall' is not a destination"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+
+			move, err := ParseMove(tc.notation)
+
+			assert.So(err, testutils.ShouldEqualError, tc.expected.err)
+			if tc.expected.err == nil {
+				assert.So(move, should.Resemble, tc.expected.move)
+				roundTrip, err := ParseMove(move.String())
+				assert.So(err, should.BeNil)
+				assert.So(roundTrip, should.Resemble, move)
+			}
+		})
+	}
+}
@@ -65,78 +65,109 @@ func (b *Board) ResetPatternLines() {
 }
 
 func (b *Board) ResetPatternLine(rowNumber int) {
-	if len(b.PatternLines[rowNumber]) > 0 {
-		for i := 0; i < len(b.PatternLines[rowNumber]); i++ {
-			//TODO: This function needs to be at the game level to access game.DiscardPile
+	b.PatternLines[rowNumber] = make([]Tile, 0, rowNumber+1)
+}
+
+// ValidatePlacement checks whether tiles of the given color may be placed on the pattern line.
+// Tiles may always be placed on the floor. A pattern line only accepts tiles if it isn't full,
+// it doesn't already hold tiles of another color, and the matching wall space is still empty.
+func (b *Board) ValidatePlacement(patternLineNumber int, color TileColor) error {
+	if patternLineNumber == FloorLine {
+		return nil
+	}
+	if patternLineNumber < 0 || patternLineNumber >= NumPatternLines {
+		return InvalidActionError{Message: fmt.Sprintf("There is no pattern line #%d", patternLineNumber)}
+	}
+
+	currentLine := b.PatternLines[patternLineNumber]
+	if len(currentLine) >= cap(currentLine) {
+		return InvalidActionError{Message: "The line is already full of tiles, please choose another line"}
+	}
+	if len(currentLine) > 0 && currentLine[0].Color != color {
+		return InvalidActionError{Message: fmt.Sprintf("The line already has %s tiles on it, please choose another line", currentLine[0].Color)}
+	}
+	for _, space := range b.Wall[patternLineNumber] {
+		if space.Color == color && space.HasTile {
+			return InvalidActionError{Message: fmt.Sprintf("The wall already has a %s tile in row #%d, please choose another line", color, patternLineNumber)}
 		}
 	}
 
-	b.PatternLines[rowNumber] = make([]Tile, 0, rowNumber+1)
+	return nil
 }
 
-func (b *Board) PlaceTiles(patternLineNumber int, tiles []Tile) error {
+// PlaceTiles puts the tiles on the pattern line, or on the floor if patternLineNumber is
+// FloorLine. Tiles that don't fit on the pattern line fall to the floor, and tiles that
+// don't fit on the floor are returned so they can be discarded.
+func (b *Board) PlaceTiles(patternLineNumber int, tiles []Tile) ([]Tile, error) {
+	var color TileColor
+	for _, tile := range tiles {
+		if tile.Color != FirstPlayerTile {
+			color = tile.Color
+			break
+		}
+	}
+	if err := b.ValidatePlacement(patternLineNumber, color); err != nil {
+		return nil, err
+	}
+
+	if patternLineNumber == FloorLine {
+		return b.AddToFloor(tiles), nil
+	}
+
 	currentLine := b.PatternLines[patternLineNumber]
 	maxTiles := cap(currentLine)
 	currentTiles := len(currentLine)
 
-	// If the line is already full, return an error
-	if currentTiles >= maxTiles {
-		return InvalidActionError{Message: "The line is already full of tiles, please choose another line"}
-	}
-
+	floorTiles := make([]Tile, 0)
 	for _, tile := range tiles {
 		// If the pattern line is full, place the tile on the Floor instead
 		// Also, if this is the 1st player tile, place it on the floor
 		if currentTiles >= maxTiles || tile.Color == FirstPlayerTile {
-			if err := b.AddToFloor([]Tile{tile}); err != nil {
-				return err
-			}
+			floorTiles = append(floorTiles, tile)
 		} else {
 			b.PatternLines[patternLineNumber] = append(b.PatternLines[patternLineNumber], tile)
 			currentTiles++
 		}
 	}
 
-	return nil
+	return b.AddToFloor(floorTiles), nil
 }
 
 func (b *Board) ResetFloor() {
-	if len(b.Floor) > 0 {
-		for i := 0; i < len(b.Floor); i++ {
-			//TODO: This function needs to be at the game level to access game.DiscardPile
-			/*
-				if b.Floor[i].Color == FirstPlayerTile {
-					//TODO: Set the first player attribute, and move the tile to the center of the table
-				} else {
-					//TODO: Discard this tile
-				}
-			*/
-		}
-	}
-
 	b.Floor = make([]FloorSpace, 0, NumFloorSpaces)
 }
 
-func (b *Board) AddToFloor(tiles []Tile) error {
+// AddToFloor puts the tiles on the floor, and returns the tiles that didn't fit. The first
+// player tile always stays on the board, because it decides who goes first in the next round,
+// so on a full floor it takes the last space and the tile that was there is returned instead.
+func (b *Board) AddToFloor(tiles []Tile) []Tile {
 	maxTiles := cap(b.Floor)
 	currentTiles := len(b.Floor)
 
+	overflow := make([]Tile, 0)
 	for _, tile := range tiles {
-		if currentTiles >= maxTiles {
-			// If there are more tiles than floor spaces, just throw out the extra tiles
-			continue
+		if currentTiles >= maxTiles && tile.Color == FirstPlayerTile && currentTiles > 0 {
+			last := &b.Floor[currentTiles-1]
+			overflow = append(overflow, last.Tile)
+			last.Tile = tile
+		} else if currentTiles >= maxTiles {
+			// If there are more tiles than floor spaces, the extra tiles are thrown out
+			overflow = append(overflow, tile)
 		} else {
 			b.Floor = append(b.Floor, FloorSpace{Tile: tile, ScoreModifier: FloorScoreModifiers[currentTiles]})
 			currentTiles++
 		}
 	}
 
-	return nil
+	return overflow
 }
 
 const NumFloorSpaces = 7
 const NumPatternLines = 5
 
+// FloorLine is the pattern line number used to place drawn tiles directly on the floor.
+const FloorLine = -1
+
 type FloorSpace struct {
 	Tile
 	ScoreModifier int
@@ -209,30 +240,138 @@ func (b *Board) MoveTileToWall(tile Tile, rowNumber int) WallCoordinate {
 	return coord
 }
 
-// Move tiles from the pattern lines to the wall, score the tiles, and reset the pattern lines
-func (b *Board) ScorePatternLines() {
+// WallTiling describes a tile that was moved from a full pattern line to the wall.
+type WallTiling struct {
+	PatternLineNumber int
+	Tile              Tile
+	WallScore         WallScore
+	DiscardedTiles    []Tile
+}
+
+// Move tiles from the pattern lines to the wall, score the tiles, and reset the pattern lines.
+// The remaining tiles from each full pattern line are returned in the result so they can be discarded.
+func (b *Board) ScorePatternLines() []WallTiling {
+	result := make([]WallTiling, 0)
+
 	// Iterate through each pattern line
 	for i := 0; i < NumPatternLines; i++ {
 		// If the pattern line if full...
 		if len(b.PatternLines[i]) == i+1 {
 			// Move a tile of that color to the wall
-			wallCoord := b.MoveTileToWall(b.PatternLines[i][i], i)
-			removeTileFromSlice(b.PatternLines[i], i)
+			tile := b.PatternLines[i][i]
+			wallCoord := b.MoveTileToWall(tile, i)
 			// Score the new tile in the wall
 			wallScore := b.ScoreTile(wallCoord)
 			b.Score += wallScore.Score
 			// Discard all the other tiles and reset the pattern line
+			result = append(result, WallTiling{
+				PatternLineNumber: i,
+				Tile:              tile,
+				WallScore:         wallScore,
+				DiscardedTiles:    append([]Tile{}, b.PatternLines[i][:i]...),
+			})
 			b.ResetPatternLine(i)
 		}
 	}
+
+	return result
 }
 
-// Score and reset the floor
-func (b *Board) ScoreFloor() {
-	for _, tile := range b.Floor {
-		b.Score -= tile.ScoreModifier
+// FloorScore describes the penalty taken for the tiles on the floor at the end of a round.
+type FloorScore struct {
+	Penalty int
+	Tiles   []Tile
+}
+
+// Score and reset the floor. The score can't drop below zero. The tiles that were on the
+// floor are returned in the result so they can be discarded.
+func (b *Board) ScoreFloor() FloorScore {
+	result := FloorScore{
+		Tiles: make([]Tile, 0, len(b.Floor)),
+	}
+
+	for _, space := range b.Floor {
+		result.Penalty += space.ScoreModifier
+		result.Tiles = append(result.Tiles, space.Tile)
+	}
+
+	b.Score += result.Penalty
+	if b.Score < 0 {
+		b.Score = 0
 	}
 	b.ResetFloor()
+
+	return result
+}
+
+// HasCompleteRow returns true if any horizontal row of the wall is full, which ends the game.
+func (b *Board) HasCompleteRow() bool {
+	return b.CompleteRows() > 0
+}
+
+// CompleteRows returns the number of full horizontal rows on the wall
+func (b *Board) CompleteRows() int {
+	var count int
+	for row := 0; row < len(b.Wall); row++ {
+		complete := true
+		for col := 0; col < len(b.Wall[row]); col++ {
+			if !b.Wall[row][col].HasTile {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			count++
+		}
+	}
+	return count
+}
+
+// EndOfGameBonus is the number of bonus points a board earns when the game ends.
+type EndOfGameBonus struct {
+	Rows    int
+	Columns int
+	Colors  int
+}
+
+const (
+	CompleteRowBonus    = 2
+	CompleteColumnBonus = 7
+	CompleteColorBonus  = 10
+)
+
+func (b EndOfGameBonus) Total() int {
+	return b.Rows + b.Columns + b.Colors
+}
+
+// ScoreEndOfGame adds the bonus points for every complete row, column and color on the wall.
+func (b *Board) ScoreEndOfGame() EndOfGameBonus {
+	result := EndOfGameBonus{
+		Rows: b.CompleteRows() * CompleteRowBonus,
+	}
+
+	colorCounts := make(map[TileColor]int)
+	for col := 0; col < len(b.Wall[0]); col++ {
+		complete := true
+		for row := 0; row < len(b.Wall); row++ {
+			if b.Wall[row][col].HasTile {
+				colorCounts[b.Wall[row][col].Color]++
+			} else {
+				complete = false
+			}
+		}
+		if complete {
+			result.Columns += CompleteColumnBonus
+		}
+	}
+	for _, count := range colorCounts {
+		if count == len(b.Wall) {
+			result.Colors += CompleteColorBonus
+		}
+	}
+
+	b.Score += result.Total()
+	return result
 }
 
 type WallScore struct {
@@ -161,3 +161,65 @@ func TestBoard_ScoreTile(t *testing.T) {
 		})
 	}
 }
+
+func TestBoard_ScoreFloor(t *testing.T) {
+	type state struct {
+		score      int
+		floorTiles []Tile
+	}
+	type expected struct {
+		score   int
+		penalty int
+	}
+	testCases := map[string]struct {
+		state    state
+		expected expected
+	}{
+		"Empty floor, no penalty": {
+			state{
+				score: 5,
+			},
+			expected{
+				score:   5,
+				penalty: 0,
+			},
+		},
+		"Three tiles on the floor": {
+			state{
+				score:      10,
+				floorTiles: []Tile{{Color: Blue}, {Color: Blue}, {Color: FirstPlayerTile}},
+			},
+			expected{
+				score:   6,
+				penalty: -4,
+			},
+		},
+		"The score can't go below zero": {
+			state{
+				score:      2,
+				floorTiles: []Tile{{Color: Red}, {Color: Red}, {Color: Red}, {Color: Red}},
+			},
+			expected{
+				score:   0,
+				penalty: -6,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+
+			b := &Board{Score: tc.state.score}
+			b.ResetFloor()
+			b.AddToFloor(tc.state.floorTiles)
+
+			result := b.ScoreFloor()
+
+			assert.So(result.Penalty, should.Equal, tc.expected.penalty)
+			assert.So(result.Tiles, shouldEqualTileSlice, tc.state.floorTiles)
+			assert.So(b.Score, should.Equal, tc.expected.score)
+			assert.So(len(b.Floor), should.Equal, 0)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+// GameSnapshot is a copy of the complete state of a game. It doesn't share any memory with
+// the game it was taken from, and it can be serialized and restored later.
+type GameSnapshot struct {
+	Config           GameConfig
+	Seed             int64 `json:",omitempty"`
+	Round            int
+	CurrentPlayerKey int
+	GameOver         bool
+	Players          map[int]Player
+	Factories        map[int][]Tile
+	CenterOfTheTable []Tile
+	Bag              []Tile `json:",omitempty"`
+	DiscardPile      []Tile
+}
+
+// Snapshot copies the state of the game
+func (g *Game) Snapshot() GameSnapshot {
+	s := GameSnapshot{
+		Config:           g.Config.copy(),
+		Seed:             g.Seed,
+		Round:            g.Round,
+		CurrentPlayerKey: g.CurrentPlayerKey,
+		GameOver:         g.GameOver,
+		Players:          make(map[int]Player, len(g.Players)),
+		Factories:        make(map[int][]Tile, len(g.Factories)),
+		CenterOfTheTable: copyTiles(g.CenterOfTheTable.Tiles),
+		Bag:              copyTiles(g.Bag.Tiles),
+		DiscardPile:      copyTiles(g.DiscardPile),
+	}
+
+	for i, player := range g.Players {
+		s.Players[i] = player.copy()
+	}
+	for i, factory := range g.Factories {
+		s.Factories[i] = copyTiles(factory.Tiles)
+	}
+
+	return s
+}
+
+// Public returns the snapshot without the state the players can't see: the seed and the order
+// of the tiles in the bag, which together tell what will be drawn next. A game restored from a
+// public snapshot can be shown and can find its legal moves, but it won't draw the same tiles
+// as the game it came from.
+func (s GameSnapshot) Public() GameSnapshot {
+	s.Seed = 0
+	s.Bag = nil
+	return s
+}
+
+// RestoreGame creates a game from a snapshot. The options are applied after the state has
+// been restored, so options like WithObserver can be used to subscribe to the restored game.
+func RestoreGame(s GameSnapshot, opts ...NewGameOption) *Game {
+	g := &Game{
+		Config:           s.Config.copy(),
+		Seed:             s.Seed,
+		Round:            s.Round,
+		CurrentPlayerKey: s.CurrentPlayerKey,
+		GameOver:         s.GameOver,
+		Players:          make(map[int]Player, len(s.Players)),
+		Factories:        make(map[int]*Factory, len(s.Factories)),
+		CenterOfTheTable: NewTileCollection(),
+		Bag:              NewBag(),
+		DiscardPile:      copyTiles(s.DiscardPile),
+	}
+
+	for i, player := range s.Players {
+		g.Players[i] = player.copy()
+	}
+	for i, tiles := range s.Factories {
+		g.Factories[i] = NewFactory()
+		g.Factories[i].AddTiles(copyTiles(tiles))
+	}
+	g.CenterOfTheTable.AddTiles(copyTiles(s.CenterOfTheTable))
+	g.Bag.AddTiles(copyTiles(s.Bag))
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// copy returns a config with its own tile colors and factory counts
+func (c GameConfig) copy() GameConfig {
+	c.TileColors = append([]TileColor(nil), c.TileColors...)
+	factories := make(map[int]int, len(c.PlayersToFactoriesMap))
+	for players, count := range c.PlayersToFactoriesMap {
+		factories[players] = count
+	}
+	c.PlayersToFactoriesMap = factories
+	return c
+}
+
+// copy returns a player with a deep copy of the board. The pattern lines and the floor keep
+// their capacity, which is how the board knows how many tiles they can hold.
+func (p Player) copy() Player {
+	board := &Board{
+		Score:        p.Board.Score,
+		PatternLines: make(map[int][]Tile, NumPatternLines),
+		Floor:        make([]FloorSpace, len(p.Board.Floor), NumFloorSpaces),
+		Wall:         make([][]WallSpace, len(p.Board.Wall)),
+	}
+
+	for i := 0; i < NumPatternLines; i++ {
+		board.PatternLines[i] = make([]Tile, len(p.Board.PatternLines[i]), i+1)
+		copy(board.PatternLines[i], p.Board.PatternLines[i])
+	}
+	copy(board.Floor, p.Board.Floor)
+	for i := range p.Board.Wall {
+		board.Wall[i] = make([]WallSpace, len(p.Board.Wall[i]))
+		copy(board.Wall[i], p.Board.Wall[i])
+	}
+
+	p.Board = board
+	return p
+}
+
+func copyTiles(tiles []Tile) []Tile {
+	result := make([]Tile, len(tiles))
+	copy(result, tiles)
+	return result
+}
@@ -10,6 +10,7 @@ import (
 type TileCollection struct {
 	Tiles        []Tile
 	errorHandler TileCollectionErrorHandler
+	random       *rand.Rand
 }
 
 func NewTileCollection(opts ...NewTileCollectionOption) *TileCollection {
@@ -35,6 +36,14 @@ func WithErrorHandler(errorHandler TileCollectionErrorHandler) NewTileCollection
 	}
 }
 
+// WithRandomSource makes the collection draw random tiles from the given source instead of
+// the shared global source, so that a sequence of draws can be reproduced.
+func WithRandomSource(source rand.Source) NewTileCollectionOption {
+	return func(tc *TileCollection) {
+		tc.random = rand.New(source)
+	}
+}
+
 type TileCollectionErrorHandler interface {
 	HandleError(err error) error
 }
@@ -58,7 +67,12 @@ func (tc *TileCollection) DrawRandomTile() (Tile, error) {
 	}
 
 	// Choose a random tile from the slice
-	selectedIndex := rand.Intn(tileCount)
+	var selectedIndex int
+	if tc.random != nil {
+		selectedIndex = tc.random.Intn(tileCount)
+	} else {
+		selectedIndex = rand.Intn(tileCount)
+	}
 	selectedTile := tc.Tiles[selectedIndex]
 
 	// Remove that tile from the slice
@@ -109,6 +123,10 @@ func (tc *TileCollection) AddTile(t Tile) {
 	tc.Tiles = append(tc.Tiles, t)
 }
 
+func (tc *TileCollection) AddTiles(tiles []Tile) {
+	tc.Tiles = append(tc.Tiles, tiles...)
+}
+
 func (tc *TileCollection) TileCount() int {
 	return len(tc.Tiles)
 }
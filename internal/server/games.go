@@ -0,0 +1,201 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+type CreateGameRequest struct {
+	// Config is optional, the default game config is used if it's missing
+	Config *models.GameConfig
+	// Players is the number of seats at the table
+	Players int
+	// Seed is optional, a random seed is used if it's missing
+	Seed *int64
+	// Clock is optional, the game isn't timed if it's missing
+	Clock *manager.ClockConfig
+}
+
+func (s *Server) handleCreateGame(w http.ResponseWriter, r *http.Request) {
+	var request CreateGameRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config := models.DefaultGameConfig
+	if request.Config != nil {
+		config = *request.Config
+	}
+	game, token, err := s.manager.Create(config, request.Players, request.Seed, request.Clock)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, GameTokenResponse{Token: token, Game: game.State()})
+}
+
+// GameTokenResponse is returned when a game is created or joined. Token is the creator's token
+// or the seat's token, and it must be kept secret.
+type GameTokenResponse struct {
+	Token string
+	Game  manager.GameState
+}
+
+func (s *Server) handleListGames(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.manager.List())
+}
+
+func (s *Server) handleGetGame(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	writeJSON(w, http.StatusOK, game.State())
+}
+
+type JoinGameRequest struct {
+	Name string
+}
+
+func (s *Server) handleJoinGame(w http.ResponseWriter, r *http.Request, game *manager.Game, seat int) {
+	var request JoinGameRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	state, token, err := game.Join(seat, request.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GameTokenResponse{Token: token, Game: state})
+}
+
+func (s *Server) handleKickPlayer(w http.ResponseWriter, r *http.Request, game *manager.Game, seat int) {
+	state, err := game.Kick(bearerToken(r), seat)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+type ReplaceWithBotRequest struct {
+	// Bot is the kind of bot, for example "greedy"
+	Bot string
+}
+
+func (s *Server) handleReplaceWithBot(w http.ResponseWriter, r *http.Request, game *manager.Game, seat int) {
+	var request ReplaceWithBotRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	state, err := game.ReplaceWithBot(bearerToken(r), seat, request.Bot)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *Server) handleForfeitSeat(w http.ResponseWriter, r *http.Request, game *manager.Game, seat int) {
+	state, err := game.Forfeit(bearerToken(r), seat)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+type ResignRequest struct {
+	// Bot is optional. If it's set, that kind of bot takes over the seat, otherwise the seat
+	// forfeits.
+	Bot string
+}
+
+func (s *Server) handleResign(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	var request ResignRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	state, err := game.Resign(bearerToken(r), request.Bot)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+type LegalMovesResponse struct {
+	CurrentPlayer int
+	Moves         []string
+}
+
+func (s *Server) handleGetLegalMoves(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	currentPlayer, moves := game.LegalMoves()
+
+	response := LegalMovesResponse{
+		CurrentPlayer: currentPlayer,
+		Moves:         make([]string, 0, len(moves)),
+	}
+	for _, move := range moves {
+		response.Moves = append(response.Moves, move.String())
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+type MakeMoveRequest struct {
+	// Move is written in notation, for example "F2:blue>L3"
+	Move string
+}
+
+func (s *Server) handleMakeMove(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	var request MakeMoveRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	move, err := models.ParseMove(request.Move)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	state, err := game.MakeMove(bearerToken(r), move)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, state)
+}
+
+type HistoryResponse struct {
+	Players []string
+	Moves   []string
+}
+
+func (s *Server) handleGetHistory(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	players, moves := game.History()
+
+	response := HistoryResponse{
+		Players: players,
+		Moves:   make([]string, 0, len(moves)),
+	}
+	for _, move := range moves {
+		response.Moves = append(response.Moves, move.String())
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
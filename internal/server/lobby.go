@@ -0,0 +1,122 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// serveLobby routes the requests under /lobby
+func (s *Server) serveLobby(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case len(path) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.manager.Lobby())
+	case len(path) == 2 && path[1] == "tables" && r.Method == http.MethodPost:
+		s.handleOpenTable(w, r)
+	case len(path) == 3 && path[1] == "tables" && r.Method == http.MethodGet:
+		table, err := s.manager.GetTable(path[2])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, table)
+	case len(path) == 4 && path[1] == "tables" && path[3] == "join" && r.Method == http.MethodPost:
+		s.handleJoinTable(w, r, path[2])
+	case len(path) == 4 && path[1] == "tables" && path[3] == "bots" && r.Method == http.MethodPost:
+		s.handleAddBot(w, r, path[2])
+	case len(path) == 4 && path[1] == "tables" && path[3] == "start" && r.Method == http.MethodPost:
+		s.handleStartTable(w, r, path[2])
+	default:
+		writeError(w, manager.NotFoundError{Message: fmt.Sprintf("%s %s was not found", r.Method, r.URL.Path)})
+	}
+}
+
+type OpenTableRequest struct {
+	Host string
+	// Players is the number of seats at the table
+	Players int
+	// Config is optional, the default game config is used if it's missing
+	Config *models.GameConfig
+	// Clock is optional, the game isn't timed if it's missing
+	Clock *manager.ClockConfig
+}
+
+func (s *Server) handleOpenTable(w http.ResponseWriter, r *http.Request) {
+	var request OpenTableRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config := models.DefaultGameConfig
+	if request.Config != nil {
+		config = *request.Config
+	}
+	table, token, err := s.manager.OpenTable(request.Host, request.Players, config, request.Clock)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, TableTokenResponse{Token: token, Table: table})
+}
+
+// TableTokenResponse is returned when a table is opened or joined. Token is the player's seat
+// token, which is also needed to play once the game starts, and it must be kept secret.
+type TableTokenResponse struct {
+	Token string
+	Table manager.Table
+}
+
+type JoinTableRequest struct {
+	Name string
+}
+
+func (s *Server) handleJoinTable(w http.ResponseWriter, r *http.Request, id string) {
+	var request JoinTableRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	table, token, err := s.manager.JoinTable(id, request.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TableTokenResponse{Token: token, Table: table})
+}
+
+type AddBotRequest struct {
+	// Bot is the kind of bot, for example "greedy"
+	Bot string
+}
+
+func (s *Server) handleAddBot(w http.ResponseWriter, r *http.Request, id string) {
+	var request AddBotRequest
+	if err := readJSON(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	table, err := s.manager.AddBot(id, bearerToken(r), request.Bot)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, table)
+}
+
+func (s *Server) handleStartTable(w http.ResponseWriter, r *http.Request, id string) {
+	table, err := s.manager.StartTable(id, bearerToken(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, table)
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Server hosts games over a REST API. The games are held by a manager.Manager, and every move
+// is validated and applied by the models package, so clients can't put a game into a state
+// the rules don't allow.
+//
+// Creating a game, joining a seat, or opening or joining a table returns a token. Requests that
+// act for a player must send the token in an "Authorization: Bearer <token>" header: a seat's
+// token is needed to make its moves, and the creator's token (or the table host's token) is
+// needed to manage seats. Reading the state of a game doesn't need a token.
+//
+//	POST   /games                            create a game
+//	GET    /games                            list the games
+//	GET    /games/{id}                       get the state of a game
+//	POST   /games/{id}/seats/{seat}          join a game
+//	DELETE /games/{id}/seats/{seat}          kick the player out of a seat (creator only)
+//	PUT    /games/{id}/seats/{seat}          replace the player in a seat with a bot (creator only)
+//	POST   /games/{id}/seats/{seat}/forfeit  forfeit an abandoned seat (creator only)
+//	POST   /games/{id}/resign                leave the game, and forfeit or hand the seat to a bot
+//	GET    /games/{id}/moves                 list the current player's legal moves
+//	POST   /games/{id}/moves                 make a move
+//	GET    /games/{id}/history               get the moves that have been made
+//	GET    /games/{id}/ws                    receive the game's events and state over a WebSocket
+//	GET    /games/{id}/stream                receive the game's events and state as Server-Sent Events
+//
+//	GET    /lobby                            list the open tables, and the waiting, running and finished games
+//	POST   /lobby/tables                     open a table
+//	GET    /lobby/tables/{id}                get a table
+//	POST   /lobby/tables/{id}/join           take a seat at a table
+//	POST   /lobby/tables/{id}/bots           fill a seat at a table with a bot (host only)
+//	POST   /lobby/tables/{id}/start          start the game at a table before it's full (host only)
+type Server struct {
+	manager *manager.Manager
+}
+
+type NewServerOption func(s *Server)
+
+// WithManager sets the manager that holds the server's games, so they can be shared with
+// other servers. A new manager is used if it isn't set.
+func WithManager(m *manager.Manager) NewServerOption {
+	return func(s *Server) {
+		s.manager = m
+	}
+}
+
+func NewServer(opts ...NewServerOption) *Server {
+	s := &Server{
+		manager: manager.NewManager(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if path[0] == "lobby" {
+		s.serveLobby(w, r, path)
+		return
+	}
+	if path[0] != "games" {
+		writeError(w, manager.NotFoundError{Message: fmt.Sprintf("%s was not found", r.URL.Path)})
+		return
+	}
+
+	if len(path) == 1 {
+		switch r.Method {
+		case http.MethodPost:
+			s.handleCreateGame(w, r)
+		case http.MethodGet:
+			s.handleListGames(w, r)
+		default:
+			writeError(w, manager.NotFoundError{Message: fmt.Sprintf("%s %s was not found", r.Method, r.URL.Path)})
+		}
+		return
+	}
+
+	game, err := s.manager.Get(path[1])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch {
+	case len(path) == 2 && r.Method == http.MethodGet:
+		s.handleGetGame(w, r, game)
+	case len(path) == 4 && path[2] == "seats":
+		seat, err := strconv.Atoi(path[3])
+		if err != nil {
+			writeError(w, manager.NotFoundError{Message: fmt.Sprintf("'%s' is not a seat", path[3])})
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			s.handleJoinGame(w, r, game, seat)
+		case http.MethodDelete:
+			s.handleKickPlayer(w, r, game, seat)
+		case http.MethodPut:
+			s.handleReplaceWithBot(w, r, game, seat)
+		default:
+			writeError(w, manager.NotFoundError{Message: fmt.Sprintf("%s %s was not found", r.Method, r.URL.Path)})
+		}
+	case len(path) == 5 && path[2] == "seats" && path[4] == "forfeit" && r.Method == http.MethodPost:
+		seat, err := strconv.Atoi(path[3])
+		if err != nil {
+			writeError(w, manager.NotFoundError{Message: fmt.Sprintf("'%s' is not a seat", path[3])})
+			return
+		}
+		s.handleForfeitSeat(w, r, game, seat)
+	case len(path) == 3 && path[2] == "resign" && r.Method == http.MethodPost:
+		s.handleResign(w, r, game)
+	case len(path) == 3 && path[2] == "moves" && r.Method == http.MethodGet:
+		s.handleGetLegalMoves(w, r, game)
+	case len(path) == 3 && path[2] == "moves" && r.Method == http.MethodPost:
+		s.handleMakeMove(w, r, game)
+	case len(path) == 3 && path[2] == "history" && r.Method == http.MethodGet:
+		s.handleGetHistory(w, r, game)
+	case len(path) == 3 && path[2] == "ws" && r.Method == http.MethodGet:
+		s.handleWebSocket(w, r, game)
+	case len(path) == 3 && path[2] == "stream" && r.Method == http.MethodGet:
+		s.handleStream(w, r, game)
+	default:
+		writeError(w, manager.NotFoundError{Message: fmt.Sprintf("%s %s was not found", r.Method, r.URL.Path)})
+	}
+}
+
+type ErrorResponse struct {
+	Error string
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.As(err, &manager.NotFoundError{}) {
+		status = http.StatusNotFound
+	} else if errors.As(err, &manager.ConflictError{}) {
+		status = http.StatusConflict
+	} else if errors.As(err, &manager.ForbiddenError{}) {
+		status = http.StatusForbidden
+	} else if errors.As(err, &models.InvalidActionError{}) {
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		fmt.Printf("failed to write the response: %s\n", err)
+	}
+}
+
+// bearerToken returns the token from the request's Authorization header
+func bearerToken(r *http.Request) string {
+	return strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+}
+
+// maxRequestBodySize is far more than any request needs, even one with a game config, so a
+// client can't make the server read an endless body
+const maxRequestBodySize = 64 << 10
+
+func readJSON(w http.ResponseWriter, r *http.Request, body interface{}) error {
+	if r.Body == nil {
+		return nil
+	}
+	// An empty body is the same as an empty JSON object
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(body); err != nil && err != io.EOF {
+		return models.InvalidActionError{Message: fmt.Sprintf("The request body isn't valid JSON: %s", err)}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// request sends a request to the handler, decodes the JSON response into response (if it
+// isn't nil), and returns the status code
+func request(t *testing.T, handler http.Handler, method, path string, body, response interface{}) int {
+	return requestWithToken(t, handler, method, path, "", body, response)
+}
+
+// requestWithToken sends a request with the token in the Authorization header
+func requestWithToken(t *testing.T, handler http.Handler, method, path, token string, body, response interface{}) int {
+	var reader bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reader).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if response != nil {
+		if err := json.NewDecoder(recorder.Body).Decode(response); err != nil {
+			t.Fatalf("failed to decode the response to %s %s: %s", method, path, err)
+		}
+	}
+	return recorder.Code
+}
+
+// createTestGame creates a game, and returns it along with the creator's token
+func createTestGame(t *testing.T, handler http.Handler, players int) (manager.GameState, string) {
+	seed := int64(1)
+	var response GameTokenResponse
+	status := request(t, handler, http.MethodPost, "/games", CreateGameRequest{Players: players, Seed: &seed}, &response)
+	if status != http.StatusCreated {
+		t.Fatalf("failed to create a game: %d", status)
+	}
+	return response.Game, response.Token
+}
+
+func TestServer_CreateGame(t *testing.T) {
+	testCases := map[string]struct {
+		body           CreateGameRequest
+		expectedStatus int
+	}{
+		"Two players":             {body: CreateGameRequest{Players: 2}, expectedStatus: http.StatusCreated},
+		"Four players":            {body: CreateGameRequest{Players: 4}, expectedStatus: http.StatusCreated},
+		"Error case: one player":  {body: CreateGameRequest{Players: 1}, expectedStatus: http.StatusBadRequest},
+		"Error case: six players": {body: CreateGameRequest{Players: 6}, expectedStatus: http.StatusBadRequest},
+		"Error case: an invalid config": {
+			body:           CreateGameRequest{Players: 2, Config: &models.GameConfig{TilesPerColor: 1000000, TilesPerFactory: 4}},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+
+			var response GameTokenResponse
+			status := request(t, NewServer(), http.MethodPost, "/games", tc.body, &response)
+
+			assert.So(status, should.Equal, tc.expectedStatus)
+			if status == http.StatusCreated {
+				assert.So(response.Token, should.NotBeEmpty)
+				assert.So(response.Game.Status, should.Equal, manager.GameStatusWaiting)
+				assert.So(len(response.Game.Seats), should.Equal, tc.body.Players)
+				assert.So(len(response.Game.State.Players), should.Equal, tc.body.Players)
+			}
+		})
+	}
+}
+
+func TestServer_PlayGame(t *testing.T) {
+	assert := assertions.New(t)
+	s := NewServer()
+	game, creatorToken := createTestGame(t, s, 2)
+	gamePath := "/games/" + game.ID
+
+	// Moves can't be made until everyone has joined
+	var moves LegalMovesResponse
+	assert.So(request(t, s, http.MethodGet, gamePath+"/moves", nil, &moves), should.Equal, http.StatusOK)
+	assert.So(moves.Moves, should.BeEmpty)
+	assert.So(request(t, s, http.MethodPost, gamePath+"/moves", MakeMoveRequest{Move: "F0:blue>L0"}, nil), should.Equal, http.StatusConflict)
+
+	var alice, bob GameTokenResponse
+	assert.So(request(t, s, http.MethodPost, gamePath+"/seats/0", JoinGameRequest{Name: "alice"}, &alice), should.Equal, http.StatusOK)
+	assert.So(request(t, s, http.MethodPost, gamePath+"/seats/0", JoinGameRequest{Name: "eve"}, nil), should.Equal, http.StatusConflict)
+	assert.So(request(t, s, http.MethodPost, gamePath+"/seats/5", JoinGameRequest{Name: "eve"}, nil), should.Equal, http.StatusNotFound)
+	assert.So(request(t, s, http.MethodPost, gamePath+"/seats/1", JoinGameRequest{Name: "bob"}, &bob), should.Equal, http.StatusOK)
+	assert.So(bob.Game.Status, should.Equal, manager.GameStatusPlaying)
+	assert.So(bob.Game.State.Players[1].Name, should.Equal, "bob")
+	// The seed and the bag would tell the players which tiles come next
+	assert.So(bob.Game.State.Seed, should.Equal, 0)
+	assert.So(bob.Game.State.Bag, should.BeEmpty)
+
+	assert.So(request(t, s, http.MethodGet, gamePath+"/moves", nil, &moves), should.Equal, http.StatusOK)
+	assert.So(moves.Moves, should.NotBeEmpty)
+
+	var errorResponse ErrorResponse
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/moves", alice.Token, MakeMoveRequest{Move: "F0:purple>L0"}, &errorResponse), should.Equal, http.StatusBadRequest)
+	assert.So(errorResponse.Error, should.ContainSubstring, "'purple' is not a tile color")
+
+	// Only alice can make the first move
+	assert.So(request(t, s, http.MethodPost, gamePath+"/moves", MakeMoveRequest{Move: moves.Moves[0]}, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/moves", bob.Token, MakeMoveRequest{Move: moves.Moves[0]}, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/moves", creatorToken, MakeMoveRequest{Move: moves.Moves[0]}, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/moves", alice.Token, MakeMoveRequest{Move: moves.Moves[0]}, &game), should.Equal, http.StatusOK)
+	assert.So(game.MoveCount, should.Equal, 1)
+	assert.So(game.CurrentPlayer, should.Equal, 1)
+
+	var history HistoryResponse
+	assert.So(request(t, s, http.MethodGet, gamePath+"/history", nil, &history), should.Equal, http.StatusOK)
+	assert.So(history.Players, should.Resemble, []string{"alice", "bob"})
+	assert.So(history.Moves, should.Resemble, []string{moves.Moves[0]})
+
+	var games []manager.GameSummary
+	assert.So(request(t, s, http.MethodGet, "/games", nil, &games), should.Equal, http.StatusOK)
+	assert.So(len(games), should.Equal, 1)
+
+	// The creator replaces bob with a bot, which makes bob's move right away
+	assert.So(requestWithToken(t, s, http.MethodPut, gamePath+"/seats/1", bob.Token, ReplaceWithBotRequest{Bot: "greedy"}, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPut, gamePath+"/seats/1", creatorToken, ReplaceWithBotRequest{Bot: "greedy"}, &game), should.Equal, http.StatusOK)
+	assert.So(game.MoveCount, should.Equal, 2)
+
+	assert.So(requestWithToken(t, s, http.MethodDelete, gamePath+"/seats/0", alice.Token, nil, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodDelete, gamePath+"/seats/0", creatorToken, nil, &game), should.Equal, http.StatusOK)
+	assert.So(game.Status, should.Equal, manager.GameStatusWaiting)
+}
+
+func TestServer_RequestTooLarge(t *testing.T) {
+	assert := assertions.New(t)
+	s := NewServer()
+	game, _ := createTestGame(t, s, 2)
+
+	var errorResponse ErrorResponse
+	status := request(t, s, http.MethodPost, "/games/"+game.ID+"/seats/0", JoinGameRequest{Name: strings.Repeat("a", maxRequestBodySize)}, &errorResponse)
+	assert.So(status, should.Equal, http.StatusBadRequest)
+	assert.So(errorResponse.Error, should.ContainSubstring, "too large")
+	assert.So(request(t, s, http.MethodPost, "/games/"+game.ID+"/seats/0", JoinGameRequest{Name: "alice"}, nil), should.Equal, http.StatusOK)
+}
+
+func TestServer_Resign(t *testing.T) {
+	assert := assertions.New(t)
+	s := NewServer()
+	game, creatorToken := createTestGame(t, s, 3)
+	gamePath := "/games/" + game.ID
+
+	var alice, bob GameTokenResponse
+	request(t, s, http.MethodPost, gamePath+"/seats/0", JoinGameRequest{Name: "alice"}, &alice)
+	request(t, s, http.MethodPost, gamePath+"/seats/1", JoinGameRequest{Name: "bob"}, &bob)
+	request(t, s, http.MethodPost, gamePath+"/seats/2", JoinGameRequest{Name: "carol"}, nil)
+
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/resign", "guess", nil, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/resign", alice.Token, ResignRequest{Bot: "greedy"}, &game), should.Equal, http.StatusOK)
+	assert.So(game.Seats[0].Bot, should.Equal, "greedy")
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/resign", bob.Token, nil, &game), should.Equal, http.StatusOK)
+	assert.So(game.Seats[1].Forfeited, should.BeTrue)
+
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/seats/2/forfeit", bob.Token, nil, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/seats/2/forfeit", creatorToken, nil, &game), should.Equal, http.StatusOK)
+	assert.So(game.Status, should.Equal, manager.GameStatusFinished)
+	assert.So(game.Winners, should.Resemble, []int{0})
+}
+
+func TestServer_NotFound(t *testing.T) {
+	assert := assertions.New(t)
+	s := NewServer()
+
+	assert.So(request(t, s, http.MethodGet, "/games/42", nil, nil), should.Equal, http.StatusNotFound)
+	assert.So(request(t, s, http.MethodGet, "/players", nil, nil), should.Equal, http.StatusNotFound)
+	assert.So(request(t, s, http.MethodDelete, "/games", nil, nil), should.Equal, http.StatusNotFound)
+}
+
+func TestServer_Lobby(t *testing.T) {
+	assert := assertions.New(t)
+	s := NewServer()
+
+	var alice, bob TableTokenResponse
+	assert.So(request(t, s, http.MethodPost, "/lobby/tables", OpenTableRequest{Host: "alice", Players: 3}, &alice), should.Equal, http.StatusCreated)
+	tablePath := "/lobby/tables/" + alice.Table.ID
+
+	assert.So(request(t, s, http.MethodPost, tablePath+"/join", JoinTableRequest{Name: "bob"}, &bob), should.Equal, http.StatusOK)
+	assert.So(requestWithToken(t, s, http.MethodPost, tablePath+"/start", bob.Token, nil, nil), should.Equal, http.StatusForbidden)
+	assert.So(requestWithToken(t, s, http.MethodPost, tablePath+"/bots", bob.Token, AddBotRequest{Bot: "greedy"}, nil), should.Equal, http.StatusForbidden)
+
+	var table manager.Table
+	assert.So(requestWithToken(t, s, http.MethodPost, tablePath+"/bots", alice.Token, AddBotRequest{Bot: "greedy"}, &table), should.Equal, http.StatusOK)
+	assert.So(table.GameID, should.NotBeEmpty)
+
+	var game manager.GameState
+	assert.So(request(t, s, http.MethodGet, "/games/"+table.GameID, nil, &game), should.Equal, http.StatusOK)
+	assert.So(game.Status, should.Equal, manager.GameStatusPlaying)
+
+	var lobby manager.Lobby
+	assert.So(request(t, s, http.MethodGet, "/lobby", nil, &lobby), should.Equal, http.StatusOK)
+	assert.So(lobby.Tables, should.BeEmpty)
+	assert.So(len(lobby.Running), should.Equal, 1)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+)
+
+// handleStream sends the game's events and state to the client as Server-Sent Events, until
+// the client disconnects. Each event is named after the message's type, and its data is the
+// message as JSON, the same as the messages sent over the WebSocket. The first event is
+// always the current state of the game.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("streaming isn't supported by this connection"))
+		return
+	}
+
+	initialState, sub := game.Subscribe()
+	defer sub.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeServerSentEvent(w, manager.Message{Type: manager.MessageTypeState, Game: &initialState}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case message, ok := <-sub.Messages:
+			if !ok {
+				return
+			}
+			if err := writeServerSentEvent(w, message); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, message manager.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", message.Type, data)
+	return err
+}
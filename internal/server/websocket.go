@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+)
+
+var upgrader = websocket.Upgrader{
+	// Players connect from other machines on the network, so requests from any origin are allowed
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// handleWebSocket pushes the game's events and state to the client until it disconnects.
+// The first message is always the current state of the game.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request, game *manager.Game) {
+	initialState, sub := game.Subscribe()
+	defer sub.Cancel()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded with an error
+		return
+	}
+	defer conn.Close()
+
+	// The client doesn't send anything, but the connection has to be read to notice when it closes
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if err := conn.WriteJSON(manager.Message{Type: manager.MessageTypeState, Game: &initialState}); err != nil {
+		return
+	}
+	for {
+		select {
+		case message, ok := <-sub.Messages:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(message); err != nil {
+				return
+			}
+		case <-disconnected:
+			return
+		}
+	}
+}
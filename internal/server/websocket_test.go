@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/manager"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// receivedMessage is a Message as a client decodes it, without knowing the event's type in advance
+type receivedMessage struct {
+	Type  string
+	Event json.RawMessage
+	Game  *manager.GameState
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) receivedMessage {
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var message receivedMessage
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func TestServer_WebSocket(t *testing.T) {
+	assert := assertions.New(t)
+	s := NewServer()
+	httpServer := httptest.NewServer(s)
+	defer httpServer.Close()
+
+	game, _ := createTestGame(t, s, 2)
+	gamePath := "/games/" + game.ID
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + gamePath + "/ws"
+	player, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.So(err, should.BeNil)
+	defer player.Close()
+	spectator, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.So(err, should.BeNil)
+	defer spectator.Close()
+
+	for _, conn := range []*websocket.Conn{player, spectator} {
+		message := readMessage(t, conn)
+		assert.So(message.Type, should.Equal, manager.MessageTypeState)
+		assert.So(message.Game.Status, should.Equal, manager.GameStatusWaiting)
+	}
+
+	var alice GameTokenResponse
+	request(t, s, http.MethodPost, gamePath+"/seats/0", JoinGameRequest{Name: "alice"}, &alice)
+	request(t, s, http.MethodPost, gamePath+"/seats/1", JoinGameRequest{Name: "bob"}, nil)
+
+	var moves LegalMovesResponse
+	request(t, s, http.MethodGet, gamePath+"/moves", nil, &moves)
+	move, err := models.ParseMove(moves.Moves[0])
+	assert.So(err, should.BeNil)
+	assert.So(requestWithToken(t, s, http.MethodPost, gamePath+"/moves", alice.Token, MakeMoveRequest{Move: moves.Moves[0]}, nil), should.Equal, http.StatusOK)
+
+	for _, conn := range []*websocket.Conn{player, spectator} {
+		// One state message for each player that joined
+		assert.So(readMessage(t, conn).Type, should.Equal, manager.MessageTypeState)
+		assert.So(readMessage(t, conn).Type, should.Equal, manager.MessageTypeState)
+
+		message := readMessage(t, conn)
+		assert.So(message.Type, should.Equal, string(models.EventTilesDrawn))
+		var drawn models.TilesDrawn
+		assert.So(json.Unmarshal(message.Event, &drawn), should.BeNil)
+		assert.So(drawn.PlayerName, should.Equal, "alice")
+		assert.So(drawn.Move, should.Resemble, move)
+
+		// The rest of the move's events are followed by the new state of the game
+		for message.Type != manager.MessageTypeState {
+			message = readMessage(t, conn)
+		}
+		assert.So(message.Game.MoveCount, should.Equal, 1)
+	}
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"bufio"
+	"io"
+)
+
+// Key is a key the player pressed, as far as the full-screen mode cares about it
+type Key int
+
+const (
+	KeyNone Key = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	// KeyEnter chooses the thing under the cursor. The space bar is an enter too.
+	KeyEnter
+	// KeyBack goes back to the previous choice. Escape and backspace are a back.
+	KeyBack
+	// KeyQuit leaves the game. 'q', Ctrl-C and Ctrl-D are a quit, and so is the end of the input.
+	KeyQuit
+)
+
+// readKey reads the next key press. The arrow keys arrive as escape sequences, for example
+// "\x1b[A" for up. The escape key on its own is told apart from the start of a sequence by
+// there being nothing else to read right after it, since a terminal sends a whole sequence at
+// once. The vi keys h, j, k and l work as arrows as well.
+func readKey(r *bufio.Reader) (Key, error) {
+	b, err := r.ReadByte()
+	if err == io.EOF {
+		return KeyQuit, nil
+	}
+	if err != nil {
+		return KeyNone, err
+	}
+
+	switch b {
+	case '\r', '\n', ' ':
+		return KeyEnter, nil
+	case 127, '\b':
+		return KeyBack, nil
+	case 3, 4, 'q', 'Q':
+		return KeyQuit, nil
+	case 'k':
+		return KeyUp, nil
+	case 'j':
+		return KeyDown, nil
+	case 'h':
+		return KeyLeft, nil
+	case 'l':
+		return KeyRight, nil
+	case '\x1b':
+		if r.Buffered() == 0 {
+			return KeyBack, nil
+		}
+		return readEscapeSequence(r), nil
+	}
+	return KeyNone, nil
+}
+
+// readEscapeSequence reads the rest of a sequence that started with an escape. Terminals send
+// the arrow keys as "\x1b[A" or, in application mode, "\x1bOA".
+func readEscapeSequence(r *bufio.Reader) Key {
+	b, err := r.ReadByte()
+	if err != nil {
+		return KeyNone
+	}
+	if b != '[' && b != 'O' {
+		// An Alt key combination
+		return KeyNone
+	}
+
+	// The sequence ends with a letter, after any numbers that go with it
+	for {
+		if b, err = r.ReadByte(); err != nil {
+			return KeyNone
+		}
+		if b < '@' || b > '~' {
+			continue
+		}
+		switch b {
+		case 'A':
+			return KeyUp
+		case 'B':
+			return KeyDown
+		case 'C':
+			return KeyRight
+		case 'D':
+			return KeyLeft
+		}
+		return KeyNone
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+)
+
+func TestReadKey(t *testing.T) {
+	testCases := map[string]struct {
+		input       string
+		expectedKey Key
+	}{
+		"Up arrow":                   {input: "\x1b[A", expectedKey: KeyUp},
+		"Down arrow":                 {input: "\x1b[B", expectedKey: KeyDown},
+		"Right arrow":                {input: "\x1b[C", expectedKey: KeyRight},
+		"Left arrow":                 {input: "\x1b[D", expectedKey: KeyLeft},
+		"Arrow in application mode":  {input: "\x1bOA", expectedKey: KeyUp},
+		"Arrow with a modifier":      {input: "\x1b[1;5C", expectedKey: KeyRight},
+		"Another escape sequence":    {input: "\x1b[3~", expectedKey: KeyNone},
+		"Escape on its own":          {input: "\x1b", expectedKey: KeyBack},
+		"Backspace":                  {input: "\x7f", expectedKey: KeyBack},
+		"Enter":                      {input: "\n", expectedKey: KeyEnter},
+		"Space":                      {input: " ", expectedKey: KeyEnter},
+		"vi keys":                    {input: "j", expectedKey: KeyDown},
+		"q":                          {input: "q", expectedKey: KeyQuit},
+		"Ctrl-C":                     {input: "\x03", expectedKey: KeyQuit},
+		"Nothing to read":            {input: "", expectedKey: KeyQuit},
+		"A key that isn't used":      {input: "x", expectedKey: KeyNone},
+		"Half of an escape sequence": {input: "\x1b[", expectedKey: KeyNone},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			key, err := readKey(bufio.NewReader(strings.NewReader(tc.input)))
+			assert.So(err, should.BeNil)
+			assert.So(key, should.Equal, tc.expectedKey)
+		})
+	}
+}
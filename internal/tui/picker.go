@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// stage is the part of a move the player is choosing
+type stage int
+
+const (
+	chooseSource stage = iota
+	chooseColor
+	chooseLine
+)
+
+// picker keeps track of the player's choices while they build a move with the keys: first the
+// factory or the center, then the color, then the pattern line or the floor. Only the choices
+// that lead to a legal move are offered, so every move the picker returns can be taken.
+type picker struct {
+	moves []models.Move
+	stage stage
+
+	// The cursor of each stage is an index into the choices of that stage
+	source, color, line int
+}
+
+func newPicker(game *models.Game) *picker {
+	return &picker{moves: game.LegalMoves()}
+}
+
+// sources are the draw sources the player can choose, in the order of the legal moves: the
+// factories, and then the center of the table. The moves only have their draw source set.
+func (p *picker) sources() []models.Move {
+	var sources []models.Move
+	for _, move := range p.moves {
+		source := models.Move{DrawSourceType: move.DrawSourceType, FactoryNumber: move.FactoryNumber}
+		if len(sources) == 0 || sources[len(sources)-1] != source {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// colors are the colors the player can draw from the chosen source
+func (p *picker) colors() []models.TileColor {
+	var colors []models.TileColor
+	source := p.chosenSource()
+	for _, move := range p.moves {
+		if move.DrawSourceType != source.DrawSourceType || move.FactoryNumber != source.FactoryNumber {
+			continue
+		}
+		if len(colors) == 0 || colors[len(colors)-1] != move.TileColor {
+			colors = append(colors, move.TileColor)
+		}
+	}
+	return colors
+}
+
+// lines are the pattern lines the chosen tiles can be placed on, and then the floor
+func (p *picker) lines() []int {
+	var lines []int
+	source, color := p.chosenSource(), p.chosenColor()
+	for _, move := range p.moves {
+		if move.DrawSourceType == source.DrawSourceType && move.FactoryNumber == source.FactoryNumber && move.TileColor == color {
+			lines = append(lines, move.PatternLineNumber)
+		}
+	}
+	return lines
+}
+
+func (p *picker) chosenSource() models.Move {
+	return p.sources()[p.source]
+}
+
+func (p *picker) chosenColor() models.TileColor {
+	return p.colors()[p.color]
+}
+
+func (p *picker) chosenLine() int {
+	return p.lines()[p.line]
+}
+
+// move is the move the player has chosen so far. The fields of the stages after the current
+// one aren't set.
+func (p *picker) move() models.Move {
+	move := p.chosenSource()
+	if p.stage > chooseSource {
+		move.TileColor = p.chosenColor()
+	}
+	if p.stage > chooseColor {
+		move.PatternLineNumber = p.chosenLine()
+	}
+	return move
+}
+
+// handle moves the cursor or makes a choice. Once the pattern line has been chosen, it returns
+// the whole move and true.
+func (p *picker) handle(key Key) (models.Move, bool) {
+	cursor, choices := &p.source, len(p.sources())
+	switch p.stage {
+	case chooseColor:
+		cursor, choices = &p.color, len(p.colors())
+	case chooseLine:
+		cursor, choices = &p.line, len(p.lines())
+	}
+
+	switch key {
+	case KeyLeft, KeyUp:
+		*cursor = (*cursor + choices - 1) % choices
+	case KeyRight, KeyDown:
+		*cursor = (*cursor + 1) % choices
+	case KeyBack:
+		if p.stage > chooseSource {
+			p.stage--
+		}
+	case KeyEnter:
+		if p.stage == chooseLine {
+			return p.move(), true
+		}
+		p.stage++
+		// The choices of the next stage depend on this one, so its cursor starts over
+		if p.stage == chooseColor {
+			p.color = 0
+		} else {
+			p.line = 0
+		}
+	}
+	return models.Move{}, false
+}
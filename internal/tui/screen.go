@@ -0,0 +1,377 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aaron-zeisler/azul/internal/interactions"
+	"github.com/aaron-zeisler/azul/internal/models"
+)
+
+// Screen draws the whole game on a full-screen terminal: the factories in a ring around the
+// center of the table, every player's board side by side, and the latest events. The player
+// chooses a move with the arrow keys instead of typing it. A Screen can stand in for an
+// interactions.Session wherever the game is shown and the moves are asked for.
+type Screen struct {
+	reader   *bufio.Reader
+	writer   io.Writer
+	renderer interactions.TileRenderer
+	width    int
+
+	// The events are described by a session, and the screen shows the last few lines
+	events     *interactions.Session
+	eventLines bytes.Buffer
+	log        []string
+}
+
+type NewScreenOption func(s *Screen)
+
+// WithTileRenderer sets how the tiles are drawn. The PlainRenderer is used if it isn't set.
+func WithTileRenderer(renderer interactions.TileRenderer) NewScreenOption {
+	return func(s *Screen) {
+		s.renderer = renderer
+	}
+}
+
+// WithWidth sets the number of columns on the screen, which decides how many boards fit side
+// by side. It's 80 if it isn't set.
+func WithWidth(width int) NewScreenOption {
+	return func(s *Screen) {
+		s.width = width
+	}
+}
+
+// logLength is the number of events shown at the bottom of the screen
+const logLength = 6
+
+// NewScreen creates a screen that reads key presses from r and draws on w. To read the keys
+// as they're pressed, r and w should be a Terminal's.
+func NewScreen(r io.Reader, w io.Writer, opts ...NewScreenOption) *Screen {
+	s := &Screen{
+		reader:   bufio.NewReader(r),
+		writer:   w,
+		renderer: interactions.PlainRenderer{},
+		width:    80,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.events = interactions.NewSession(strings.NewReader(""), &s.eventLines, interactions.WithTileRenderer(s.renderer))
+	return s
+}
+
+// DisplayGameState draws the game
+func (s *Screen) DisplayGameState(game *models.Game) {
+	s.draw(game, nil)
+}
+
+// DisplayEvent adds a line about the event to the bottom of the screen. It can be subscribed
+// to a game with models.ObserverFunc(screen.DisplayEvent). The screen isn't drawn again until
+// the next move is asked for or the game is displayed.
+func (s *Screen) DisplayEvent(event models.Event) {
+	s.events.DisplayEvent(event)
+	for _, line := range strings.Split(strings.TrimSpace(s.eventLines.String()), "\n") {
+		if line != "" {
+			s.log = append(s.log, line)
+		}
+	}
+	s.eventLines.Reset()
+	if len(s.log) > logLength {
+		s.log = s.log[len(s.log)-logLength:]
+	}
+}
+
+// PromptForMove lets the current player choose a move with the keys. It returns an
+// interactions.QuitError if they quit.
+func (s *Screen) PromptForMove(game *models.Game) (models.Move, error) {
+	p := newPicker(game)
+	if len(p.moves) == 0 {
+		return models.Move{}, models.InvalidActionError{Message: "There are no moves to make"}
+	}
+
+	for {
+		s.draw(game, p)
+		key, err := readKey(s.reader)
+		if err != nil {
+			return models.Move{}, err
+		}
+		if key == KeyQuit {
+			return models.Move{}, interactions.QuitError{}
+		}
+		if move, done := p.handle(key); done {
+			return move, nil
+		}
+	}
+}
+
+// draw writes the frame over the one before it, clearing what's left of each line, and then
+// everything below the frame
+func (s *Screen) draw(game *models.Game, p *picker) {
+	var frame strings.Builder
+	frame.WriteString("\x1b[H")
+	for _, line := range s.render(game, p) {
+		frame.WriteString(line)
+		frame.WriteString("\x1b[K\n")
+	}
+	frame.WriteString("\x1b[J")
+	fmt.Fprint(s.writer, frame.String())
+}
+
+// render lays out the lines of the screen. The picker is nil when nobody is choosing a move.
+func (s *Screen) render(game *models.Game, p *picker) []string {
+	title := fmt.Sprintf("AZUL - ROUND %d - %s's turn", game.Round, game.CurrentPlayer().Name)
+	if game.GameOver {
+		title = "AZUL - GAME OVER"
+	}
+	lines := []string{title}
+	if legend := interactions.LegendFor(s.renderer); legend != "" {
+		lines = append(lines, strings.Split(legend, "\n")...)
+	}
+	lines = append(lines, "")
+	lines = append(lines, s.renderTable(game, p)...)
+	lines = append(lines, "")
+
+	boards := make([][]string, len(game.Players))
+	for i := range boards {
+		boards[i] = s.renderBoard(game, i, p)
+	}
+	lines = append(lines, interactions.ArrangeInColumns(boards, s.width, 4)...)
+	lines = append(lines, "")
+
+	if p != nil {
+		lines = append(lines, s.renderPrompt(game, p)...)
+		lines = append(lines, "")
+	}
+	return append(lines, s.log...)
+}
+
+const (
+	// cursorMarker points at the choice under the cursor, and at the player whose turn it is
+	cursorMarker = "▶"
+	// legalMarker is next to the lines the chosen tiles can be placed on
+	legalMarker = "*"
+)
+
+// marked puts markers around the text if it's under the cursor, or spaces of the same width
+func marked(text string, cursor bool) string {
+	if cursor {
+		return cursorMarker + " " + text + " ◀"
+	}
+	return "  " + text + "  "
+}
+
+// renderTable draws the factories in a ring, with the center of the table in the middle
+func (s *Screen) renderTable(game *models.Game, p *picker) []string {
+	var chosen models.Move
+	if p != nil {
+		chosen = p.chosenSource()
+	}
+	isChosen := func(source models.Move) bool {
+		return p != nil && source.DrawSourceType == chosen.DrawSourceType && source.FactoryNumber == chosen.FactoryNumber
+	}
+
+	factories := make([][]string, len(game.Factories))
+	factoryWidth := 0
+	for i := range factories {
+		source := models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: i}
+		factories[i] = []string{
+			marked(fmt.Sprintf("F%d", i), isChosen(source)),
+			s.renderTiles(game.Factories[i].Tiles),
+		}
+		for _, line := range factories[i] {
+			if w := interactions.VisibleWidth(line); w > factoryWidth {
+				factoryWidth = w
+			}
+		}
+	}
+
+	center := []string{marked("Center", isChosen(models.Move{DrawSourceType: models.DrawSourceCenter}))}
+	const tilesPerRow = 5
+	tiles := game.CenterOfTheTable.Tiles
+	for start := 0; start < len(tiles) || start == 0; start += tilesPerRow {
+		end := start + tilesPerRow
+		if end > len(tiles) {
+			end = len(tiles)
+		}
+		center = append(center, s.renderTiles(tiles[start:end]))
+	}
+	centerWidth := 0
+	for _, line := range center {
+		if w := interactions.VisibleWidth(line); w > centerWidth {
+			centerWidth = w
+		}
+	}
+
+	// The ring is an ellipse that's wide enough for the factories next to each other at the
+	// top and bottom, and for the center in the middle
+	radiusX := math.Max(1.6*float64(factoryWidth)+2, float64(centerWidth+factoryWidth)/2+3)
+	radiusY := math.Max(4, float64(len(center))/2+3)
+	c := canvas{}
+	c.put(-len(center)/2, -centerWidth/2, centered(center, centerWidth))
+	for i, factory := range factories {
+		angle := -math.Pi/2 + 2*math.Pi*float64(i)/float64(len(factories))
+		row := int(math.Round(radiusY*math.Sin(angle))) - 1
+		column := int(math.Round(radiusX*math.Cos(angle))) - factoryWidth/2
+		c.put(row, column, centered(factory, factoryWidth))
+	}
+	return c.lines()
+}
+
+// renderTiles draws the tiles next to each other, or an empty space if there aren't any
+func (s *Screen) renderTiles(tiles []models.Tile) string {
+	if len(tiles) == 0 {
+		return s.renderer.EmptySpace()
+	}
+	rendered := make([]string, len(tiles))
+	for i, tile := range tiles {
+		rendered[i] = s.renderer.Tile(tile.Color)
+	}
+	return strings.Join(rendered, " ")
+}
+
+// renderBoard draws a player's pattern lines next to their wall, and their floor below. While
+// the current player chooses a pattern line, the lines they can choose are marked.
+func (s *Screen) renderBoard(game *models.Game, playerKey int, p *picker) []string {
+	player := game.Players[playerKey]
+	choosing := p != nil && p.stage == chooseLine && playerKey == game.CurrentPlayerKey
+	lineMarker := func(line int) string {
+		if !choosing {
+			return "  "
+		}
+		if line == p.chosenLine() {
+			return cursorMarker + " "
+		}
+		for _, legal := range p.lines() {
+			if line == legal {
+				return legalMarker + " "
+			}
+		}
+		return "  "
+	}
+
+	turnMarker := "  "
+	if playerKey == game.CurrentPlayerKey && !game.GameOver {
+		turnMarker = cursorMarker + " "
+	}
+	lines := []string{turnMarker + player.String()}
+
+	// The board's lines are its pattern lines, and then its floor
+	for i, line := range interactions.CompactBoard(s.renderer, player.Board) {
+		patternLine := i
+		if i == models.NumPatternLines {
+			patternLine = models.FloorLine
+		}
+		lines = append(lines, lineMarker(patternLine)+line)
+	}
+	return lines
+}
+
+// renderPrompt tells the player what they're choosing and which keys to use
+func (s *Screen) renderPrompt(game *models.Game, p *picker) []string {
+	switch p.stage {
+	case chooseColor:
+		colors := p.colors()
+		choices := make([]string, len(colors))
+		for i, color := range colors {
+			choices[i] = marked(s.renderer.Tile(color)+" "+string(color), i == p.color)
+		}
+		return []string{
+			"Colors:" + strings.Join(choices, ""),
+			"Choose a color with the arrow keys and press enter. Escape goes back, and q quits.",
+		}
+	case chooseLine:
+		move := p.move()
+		return []string{fmt.Sprintf("Choose where to place the %d %s tile(s) with the arrow keys and press enter. The lines marked %s can take them. Escape goes back, and q quits.",
+			countTiles(game, move), string(move.TileColor), legalMarker)}
+	}
+	return []string{"Choose a factory or the center of the table with the arrow keys and press enter. q quits."}
+}
+
+// countTiles counts the tiles of the move's color on its draw source
+func countTiles(game *models.Game, move models.Move) int {
+	tiles := game.CenterOfTheTable.Tiles
+	if move.DrawSourceType == models.DrawSourceFactory {
+		tiles = game.Factories[move.FactoryNumber].Tiles
+	}
+	count := 0
+	for _, tile := range tiles {
+		if tile.Color == move.TileColor {
+			count++
+		}
+	}
+	return count
+}
+
+// centered pads the lines on the left so they're centered in the width
+func centered(lines []string, width int) []string {
+	padded := make([]string, len(lines))
+	for i, line := range lines {
+		padded[i] = strings.Repeat(" ", (width-interactions.VisibleWidth(line))/2) + line
+	}
+	return padded
+}
+
+// canvas is a grid of text where blocks of lines are put at a row and column. The rows and
+// columns can be negative, for example to put things around the middle of the canvas at 0, 0.
+type canvas struct {
+	rows map[int][]segment
+}
+
+type segment struct {
+	column int
+	text   string
+}
+
+func (c *canvas) put(row int, column int, lines []string) {
+	if c.rows == nil {
+		c.rows = make(map[int][]segment)
+	}
+	for i, line := range lines {
+		c.rows[row+i] = append(c.rows[row+i], segment{column: column, text: line})
+	}
+}
+
+// lines draws the canvas, from its top row to its bottom row and from its leftmost column.
+// A segment that would overlap the one before it is moved to the right.
+func (c *canvas) lines() []string {
+	if len(c.rows) == 0 {
+		return nil
+	}
+	top, bottom, left := math.MaxInt32, math.MinInt32, math.MaxInt32
+	for row, segments := range c.rows {
+		if row < top {
+			top = row
+		}
+		if row > bottom {
+			bottom = row
+		}
+		for _, seg := range segments {
+			if seg.column < left {
+				left = seg.column
+			}
+		}
+	}
+
+	lines := make([]string, 0, bottom-top+1)
+	for row := top; row <= bottom; row++ {
+		segments := c.rows[row]
+		sort.SliceStable(segments, func(i, j int) bool { return segments[i].column < segments[j].column })
+		var line strings.Builder
+		column := left
+		for _, seg := range segments {
+			if seg.column > column {
+				line.WriteString(strings.Repeat(" ", seg.column-column))
+				column = seg.column
+			}
+			line.WriteString(seg.text)
+			column += interactions.VisibleWidth(seg.text)
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return lines
+}
@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/assertions"
+	"github.com/smartystreets/assertions/should"
+
+	"github.com/aaron-zeisler/azul/internal/interactions"
+	"github.com/aaron-zeisler/azul/internal/models"
+	"github.com/aaron-zeisler/azul/internal/testutils"
+)
+
+// newTestGame starts a game where alice can draw blue or red from factory #0, or red from
+// factory #1
+func newTestGame() *models.Game {
+	players := map[int]models.Player{
+		0: models.NewPlayer("alice", models.FirstPlayer()),
+		1: models.NewPlayer("bob"),
+	}
+	game := models.NewGame(models.WithPlayers(players), models.WithSeed(1))
+	for i, factory := range game.Factories {
+		factory.Tiles = nil
+		switch i {
+		case 0:
+			factory.Tiles = []models.Tile{{Color: models.Blue}, {Color: models.Red}}
+		case 1:
+			factory.Tiles = []models.Tile{{Color: models.Red}}
+		}
+	}
+	return game
+}
+
+// withoutEscapes takes the ANSI escape codes out of the screen's output
+func withoutEscapes(output string) string {
+	return regexp.MustCompile("\x1b\\[[0-9;?]*[@-~]").ReplaceAllString(output, "")
+}
+
+func TestScreen_PromptForMove(t *testing.T) {
+	testCases := map[string]struct {
+		input        string
+		expectedMove models.Move
+		expectedErr  error
+	}{
+		"The first choice of each stage": {
+			input:        "\n\n\n",
+			expectedMove: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 0, TileColor: models.Blue, PatternLineNumber: 0},
+		},
+		"Arrow keys": {
+			input:        "\x1b[C\n\n\x1b[B\x1b[B\n",
+			expectedMove: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Red, PatternLineNumber: 2},
+		},
+		"The cursor wraps around": {
+			input:        "\x1b[D\n\n\x1b[A\n",
+			expectedMove: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 1, TileColor: models.Red, PatternLineNumber: models.FloorLine},
+		},
+		"Going back to choose another color": {
+			input:        "\n\n\x7fl\n\n",
+			expectedMove: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 0, TileColor: models.Red, PatternLineNumber: 0},
+		},
+		"Going back from the first stage does nothing": {
+			input:        "\x7f\n\n\n",
+			expectedMove: models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 0, TileColor: models.Blue, PatternLineNumber: 0},
+		},
+		"Quit": {
+			input:       "\nq",
+			expectedErr: interactions.QuitError{},
+		},
+		"Nothing to read": {
+			input:       "\n",
+			expectedErr: interactions.QuitError{},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert := assertions.New(t)
+			game := newTestGame()
+			var output bytes.Buffer
+			s := NewScreen(strings.NewReader(tc.input), &output)
+
+			move, err := s.PromptForMove(game)
+			assert.So(err, testutils.ShouldEqualError, tc.expectedErr)
+			assert.So(move, should.Resemble, tc.expectedMove)
+			if err == nil {
+				assert.So(game.ValidateMove(move), should.BeNil)
+			}
+		})
+	}
+}
+
+func TestScreen_Render(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	s := NewScreen(strings.NewReader(""), &bytes.Buffer{}, WithWidth(300))
+	p := newPicker(game)
+
+	screen := withoutEscapes(strings.Join(s.render(game, p), "\n"))
+	assert.So(screen, should.StartWith, "AZUL - ROUND 1 - alice's turn")
+	assert.So(screen, should.ContainSubstring, "▶ F0 ◀")
+	assert.So(screen, should.ContainSubstring, "  F1")
+	assert.So(screen, should.ContainSubstring, "Center")
+	assert.So(screen, should.ContainSubstring, "Choose a factory or the center of the table")
+	// Both boards fit side by side
+	assert.So(screen, should.ContainSubstring, "▶ alice - 0 Points - First Player")
+	assert.So(screen, should.NotContainSubstring, "First Player\n")
+
+	p.handle(KeyEnter)
+	screen = withoutEscapes(strings.Join(s.render(game, p), "\n"))
+	assert.So(screen, should.ContainSubstring, "Colors:▶ {  blue} blue ◀  {   red} red")
+
+	// Blue can go on any of alice's lines, and the cursor starts on the first one
+	p.handle(KeyEnter)
+	screen = withoutEscapes(strings.Join(s.render(game, p), "\n"))
+	assert.So(screen, should.ContainSubstring, "place the 1 blue tile(s)")
+	assert.So(screen, should.ContainSubstring, "\n▶ 0 ")
+	assert.So(screen, should.ContainSubstring, "\n* 4 ")
+	assert.So(screen, should.ContainSubstring, "\n* Floor ")
+	// bob's lines aren't marked
+	assert.So(screen, should.NotContainSubstring, "*   0")
+}
+
+func TestScreen_DisplayEvent(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	var output bytes.Buffer
+	s := NewScreen(strings.NewReader(""), &output)
+	game.Subscribe(models.ObserverFunc(s.DisplayEvent))
+
+	assert.So(game.TakeTurn(models.Move{DrawSourceType: models.DrawSourceFactory, FactoryNumber: 0, TileColor: models.Blue, PatternLineNumber: 1}), should.BeNil)
+	s.DisplayGameState(game)
+	screen := withoutEscapes(output.String())
+	assert.So(screen, should.ContainSubstring, "AZUL - ROUND 1 - bob's turn")
+	assert.So(screen, should.ContainSubstring, "alice drew 1 blue tile(s) from factory #0")
+	assert.So(screen, should.ContainSubstring, "alice placed the tiles on pattern line #1")
+}
+
+func TestScreen_Render_Symbols(t *testing.T) {
+	assert := assertions.New(t)
+	game := newTestGame()
+	s := NewScreen(strings.NewReader(""), &bytes.Buffer{}, WithTileRenderer(interactions.SymbolRenderer{}))
+
+	screen := strings.Join(s.render(game, nil), "\n")
+	assert.So(screen, should.ContainSubstring, "Tiles: B blue")
+	assert.So(screen, should.ContainSubstring, "B R")
+	assert.So(screen, should.ContainSubstring, "★")
+	assert.So(screen, should.ContainSubstring, "  0         . | b o r k w")
+	// Two boards fit side by side on 80 columns
+	assert.So(screen, should.ContainSubstring, "▶ alice - 0 Points - First Player")
+	assert.So(screen, should.NotContainSubstring, "First Player\n")
+	assert.So(screen, should.NotContainSubstring, "\x1b")
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aaron-zeisler/azul/internal/interactions"
+)
+
+const (
+	enterAlternateScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAlternateScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// Terminal is a terminal in full-screen mode: the keys are read as they're pressed, without
+// being echoed, and the game is drawn on a screen of its own, which is put away again when the
+// terminal is closed. The terminal's settings are changed with stty, so the full-screen mode
+// works on the terminals of Linux, macOS and the BSDs.
+type Terminal struct {
+	in       *os.File
+	out      *os.File
+	settings string
+}
+
+// OpenTerminal switches the terminal that in and out belong to into full-screen mode. Close
+// must be called to switch it back.
+func OpenTerminal(in *os.File, out *os.File) (*Terminal, error) {
+	settings, err := stty(in, "-g")
+	if err != nil {
+		return nil, fmt.Errorf("the full-screen mode needs a terminal: %w", err)
+	}
+	// Without -isig, Ctrl-C is read as a key, so the terminal is set back before the game ends
+	if _, err := stty(in, "-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+
+	fmt.Fprint(out, enterAlternateScreen)
+	return &Terminal{in: in, out: out, settings: settings}, nil
+}
+
+// Close puts the terminal's screen and settings back the way they were
+func (t *Terminal) Close() error {
+	fmt.Fprint(t.out, leaveAlternateScreen)
+	_, err := stty(t.in, t.settings)
+	return err
+}
+
+// Width is the number of columns on the terminal, or 80 if it can't be found out
+func (t *Terminal) Width() int {
+	if width := interactions.TerminalWidth(t.out); width > 0 {
+		return width
+	}
+	return 80
+}
+
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}